@@ -0,0 +1,199 @@
+package toml
+
+import (
+	"sort"
+	"time"
+)
+
+/**
+Clone 返回 *Value 的深度副本, 包括数组元素, ArrayOfTables 中的 Toml 以及注释.
+副本保持原来的 Id, 因此格式化输出的次序不变.
+*/
+func (p *Value) Clone() *Value {
+	if p == nil {
+		return nil
+	}
+
+	nv := *p
+	nv.multiComments = append(aString{}, p.multiComments...)
+
+	switch v := p.v.(type) {
+	case []*Value:
+		a := make([]*Value, len(v))
+		for i, e := range v {
+			a[i] = e.Clone()
+		}
+		nv.v = a
+	case TomlArray:
+		ts := make(TomlArray, len(v))
+		for i, tm := range v {
+			ts[i] = tm.Clone()
+		}
+		nv.v = ts
+	}
+	return &nv
+}
+
+// Clone 返回 Item 的深度副本, 参见 Value.Clone.
+func (i Item) Clone() Item {
+	return Item{i.Value.Clone()}
+}
+
+/**
+Clone 返回 Toml 的深度副本, 包括用于管理的 Id 以及 ArrayOfTables.
+与 Fetch 不同, 对副本的任何修改都不会影响原 Toml.
+*/
+func (p Toml) Clone() Toml {
+	if p == nil {
+		return nil
+	}
+	tm := make(Toml, len(p))
+	for key, it := range p {
+		tm[key] = it.Clone()
+	}
+	return tm
+}
+
+// EqualFlag 控制 Equal 的比较方式, 可以组合使用.
+type EqualFlag uint
+
+const (
+	// 忽略注释
+	IgnoreComments EqualFlag = 1 << iota
+	// 忽略 Key, TableName, ArrayOfTables 在文档中的次序. 数组元素的次序总是有效的.
+	IgnoreOrder
+)
+
+func equalFlags(flags []EqualFlag) (f EqualFlag) {
+	for _, flag := range flags {
+		f |= flag
+	}
+	return
+}
+
+/**
+Equal 比较 p 和 o 的内容是否相同, Kind 和值都相同才算相等.
+默认同时比较注释, 数组和 ArrayOfTables 逐个元素比较, 参见 EqualFlag.
+Item 也可以使用此方法.
+*/
+func (p *Value) Equal(o *Value, flags ...EqualFlag) bool {
+	return p.equal(o, equalFlags(flags))
+}
+
+func (p *Value) equal(o *Value, flags EqualFlag) bool {
+	if p == nil || o == nil {
+		return p == o
+	}
+
+	if p.kind != o.kind {
+		return false
+	}
+
+	if flags&IgnoreComments == 0 {
+		if p.eolComment != o.eolComment || !equalStrings(p.multiComments, o.multiComments) {
+			return false
+		}
+	}
+
+	switch a := p.v.(type) {
+	case nil:
+		return o.v == nil
+	case time.Time:
+		b, ok := o.v.(time.Time)
+		return ok && a.Equal(b)
+	case []*Value:
+		b, ok := o.v.([]*Value)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !a[i].equal(b[i], flags) {
+				return false
+			}
+		}
+		return true
+	case TomlArray:
+		b, ok := o.v.(TomlArray)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalToml(a[i], b[i], flags) {
+				return false
+			}
+		}
+		return true
+	}
+	return p.v == o.v
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+Equal 比较两个 Toml 的内容是否相同.
+默认比较全部的值, 注释以及它们在文档中的次序, flags 可以忽略注释和次序.
+	Equal(a, b)
+	Equal(a, b, IgnoreComments, IgnoreOrder)
+*/
+func Equal(a, b Toml, flags ...EqualFlag) bool {
+	return equalToml(a, b, equalFlags(flags))
+}
+
+func equalToml(a, b Toml, flags EqualFlag) bool {
+	ka, kb := a.validKeys(), b.validKeys()
+	if len(ka) != len(kb) {
+		return false
+	}
+
+	for _, k := range ka {
+		if !a[k.key].equal(b[k.key].Value, flags) {
+			return false
+		}
+	}
+
+	// Fetch 返回的 Toml 没有 iD, 不要用 Id() 自动建立
+	if flags&IgnoreComments == 0 {
+		ia, ib := a[iD].Value, b[iD].Value
+		if ia == nil {
+			ia = &Value{}
+		}
+		if ib == nil {
+			ib = &Value{}
+		}
+		if ia.eolComment != ib.eolComment || !equalStrings(ia.multiComments, ib.multiComments) {
+			return false
+		}
+	}
+
+	if flags&IgnoreOrder == 0 {
+		for i := range ka {
+			if ka[i].key != kb[i].key {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validKeys 返回按照 Id 排序的有效元素.
+func (p Toml) validKeys() sortIdx {
+	var keys sortIdx
+	for key, it := range p {
+		if key == iD || !it.IsValid() {
+			continue
+		}
+		keys = append(keys, kkId{it.kind, key, it.idx})
+	}
+	sort.Sort(keys)
+	return keys
+}
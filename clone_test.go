@@ -0,0 +1,77 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+)
+
+func TestTomlClone(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	c := tm.Clone()
+	wt.True(Equal(tm, c))
+	wt.Equal(c.String(), tm.String())
+
+	// 修改副本不影响原 Toml
+	wt.Nil(c.Set("database.ports[0]", 9000))
+	wt.Nil(c.Set("products[0].name", "Saw"))
+	wt.Nil(c.Set("clients.data[0][0]", "beta"))
+	c["owner"].SetComment("changed")
+	c[iD].SetComments([]string{"changed"})
+
+	wt.Equal(tm.Get("database.ports[0]").Integer(), 8001)
+	wt.Equal(tm.Get("products[0].name").String(), "Hammer")
+	wt.Equal(tm.Get("clients.data[0][0]").String(), "gamma")
+	wt.Equal(tm["owner"].Comment(), "# owner information")
+	wt.Equal(tm.Id().multiComments, aString{"# last comments for", "# TOML document"})
+
+	wt.False(Equal(tm, c))
+
+	iv := tm["clients.data"].Clone()
+	wt.True(iv.Equal(tm["clients.data"].Value))
+	wt.True(iv.Index(0) != tm["clients.data"].Index(0))
+}
+
+func TestTomlEqual(t *testing.T) {
+	wt := want.T(t)
+
+	a, err := Parse([]byte(`
+# comment
+title = "a"
+[owner]
+name = "Tom" # eol
+age = 30
+[[products]]
+name = "Hammer"
+`))
+	wt.Nil(err)
+
+	b, err := Parse([]byte(`
+title = "a"
+[owner]
+age = 30
+name = "Tom"
+[[products]]
+name = "Hammer"
+`))
+	wt.Nil(err)
+
+	wt.True(Equal(a, a.Clone()))
+	wt.False(Equal(a, b))
+	wt.False(Equal(a, b, IgnoreComments))
+	wt.False(Equal(a, b, IgnoreOrder))
+	wt.True(Equal(a, b, IgnoreComments, IgnoreOrder))
+
+	wt.True(a["owner.name"].Equal(b["owner.name"].Value, IgnoreComments))
+	wt.False(a["owner.name"].Equal(b["owner.name"].Value))
+	wt.True(a["products"].Equal(b["products"].Value, IgnoreComments))
+
+	wt.Nil(b.Set("products[0].name", "Nail"))
+	wt.False(Equal(a, b, IgnoreComments, IgnoreOrder))
+	wt.False(a["products"].Equal(b["products"].Value, IgnoreComments))
+
+	wt.True(Equal(a.Fetch("owner"), a.Fetch("owner")))
+	wt.Equal(len(a.Fetch("owner")), 2)
+}
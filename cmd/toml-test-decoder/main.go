@@ -0,0 +1,37 @@
+/**
+toml-test-decoder 从 stdin 读取 TOML 文档, 向 stdout 输出 toml-test 使用的 tagged JSON.
+解析失败时退出码为 1. 用法:
+	toml-test toml-test-decoder
+*/
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/achun/tom-toml"
+)
+
+func main() {
+	source, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fail(err)
+	}
+
+	tm, err := toml.Parse(source)
+	if err != nil {
+		fail(err)
+	}
+
+	b, err := tm.TaggedJSON()
+	if err != nil {
+		fail(err)
+	}
+	os.Stdout.Write(b)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
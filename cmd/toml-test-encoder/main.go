@@ -0,0 +1,32 @@
+/**
+toml-test-encoder 从 stdin 读取 toml-test 使用的 tagged JSON, 向 stdout 输出 TOML 文档.
+转换失败时退出码为 1. 用法:
+	toml-test -encoder toml-test-encoder
+*/
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/achun/tom-toml"
+)
+
+func main() {
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fail(err)
+	}
+
+	tm, err := toml.FromTaggedJSON(data)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Print(tm.String())
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,255 @@
+package toml
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CoercePolicy 决定 Lenient 允许的类型转换, 可以组合使用.
+type CoercePolicy uint
+
+const (
+	// String 转换为 Integer, Float, Boolean, Datetime 以及 time.Duration, 转换前去掉首尾空白
+	CoerceString CoercePolicy = 1 << iota
+	// Integer 转换为 Float
+	CoerceFloat
+	// 单个值转换为只有一个元素的数组, 元素本身也可以被转换, 比如 "8080" 转换为 [8080]
+	CoerceSlice
+
+	CoerceAll = CoerceString | CoerceFloat | CoerceSlice
+)
+
+// Coercion 是 Lenient 进行的一次类型转换, 用于警告.
+type Coercion struct {
+	Path  string // 访问路径, Apply 时是字段所在的路径, 比如 "server.port", "ports[0]"
+	From  Kind
+	To    string // 目标 Kind 的名称, 或者 "Duration"
+	Value string // 原来的值
+}
+
+func (c Coercion) String() string {
+	return "toml: " + c.Path + ": coerce " + c.From.String() + " " +
+		strconv.Quote(c.Value) + " to " + c.To
+}
+
+/**
+Lenient 是 Toml 的宽松视图, 其 Try, Get, Must 以及 Apply 方法按照 Policy 转换类型,
+用于把数字写成字符串等不规范的配置. 数组中的元素也会按照 Policy 转换.
+不能转换时与 Toml 的同名方法相同, 比如返回 *KindError. Policy 为 0 时不做任何转换.
+转换只发生在通过 Lenient 的方法访问的值上, Item 和 Value 的方法, 比如 Value.Int, Item.Apply,
+总是不做转换. 需要转换单个 Table 时使用 Lenient{Toml: tm.Fetch("server")}.
+
+	lt := toml.Lenient{Toml: tm, Policy: toml.CoerceAll, Warn: func(c toml.Coercion) {
+		log.Println(c)
+	}}
+	port := lt.GetInt("server.port", 80)           // port = "8080"
+	timeout := lt.GetDuration("server.timeout", 0) // timeout = "5s"
+	lt.Apply(&cfg)
+*/
+type Lenient struct {
+	Toml
+	Policy CoercePolicy
+	Warn   func(Coercion) // 可选, 每次转换时调用
+}
+
+// convert 把 v 转换为 kind, 不能转换时返回 nil.
+func (l *Lenient) convert(v *Value, kind Kind) *Value {
+	switch {
+	case v.kind == kind:
+		return v
+
+	case v.kind == String && kind > String && kind <= Datetime:
+		if l.Policy&CoerceString != 0 {
+			nv := &Value{}
+			if nv.SetAs(strings.TrimSpace(v.String()), kind) == nil {
+				return nv
+			}
+		}
+
+	case v.kind == Integer && kind == Float:
+		if l.Policy&CoerceFloat != 0 {
+			return &Value{kind: Float, v: float64(v.Int())}
+		}
+
+	case v.kind < StringArray && kind >= StringArray && kind < Array:
+		if l.Policy&CoerceSlice != 0 {
+			if e := l.convert(v, kind+String-StringArray); e != nil {
+				return &Value{kind: kind, v: []*Value{e}}
+			}
+		}
+
+	case v.kind >= StringArray && v.kind < Array && kind >= StringArray && kind < Array:
+		a, _ := v.v.([]*Value)
+		na := make([]*Value, len(a))
+		for i, e := range a {
+			if na[i] = l.convert(e, kind+String-StringArray); na[i] == nil {
+				return nil
+			}
+		}
+		return &Value{kind: kind, v: na}
+	}
+	return nil
+}
+
+// coerce 返回 v 或者由 v 转换的 kind 值, 转换时调用 Warn, l 为 nil 或者不能转换时返回 nil.
+func (l *Lenient) coerce(path string, v *Value, kind Kind) *Value {
+	if v.kind == kind {
+		return v
+	}
+	if l == nil {
+		return nil
+	}
+	nv := l.convert(v, kind)
+	if nv != nil {
+		l.warn(path, v, kind.String())
+	}
+	return nv
+}
+
+func (l *Lenient) warn(path string, v *Value, to string) {
+	if l.Warn != nil {
+		l.Warn(Coercion{path, v.kind, to, v.String()})
+	}
+}
+
+// getter 返回使用 l.coerce 转换类型的 getter.
+func (l Lenient) getter() getter {
+	return getter{l.Toml, l.coerce}
+}
+
+func (l Lenient) TryString(path string) (string, error) {
+	return l.getter().tryString(path)
+}
+
+func (l Lenient) TryInt(path string) (int64, error) {
+	return l.getter().tryInt(path)
+}
+
+func (l Lenient) TryFloat(path string) (float64, error) {
+	return l.getter().tryFloat(path)
+}
+
+func (l Lenient) TryBool(path string) (bool, error) {
+	return l.getter().tryBool(path)
+}
+
+func (l Lenient) TryTime(path string) (time.Time, error) {
+	return l.getter().tryTime(path)
+}
+
+func (l Lenient) TryStringSlice(path string) ([]string, error) {
+	return l.getter().tryStringSlice(path)
+}
+
+func (l Lenient) TryIntSlice(path string) ([]int64, error) {
+	return l.getter().tryIntSlice(path)
+}
+
+/**
+TryDuration 返回由 String 转换的 time.Duration, 比如 "1m30s", 参见 time.ParseDuration.
+TOML 没有对应的 Kind, 因此需要 CoerceString, 否则返回 NotSupported.
+*/
+func (l Lenient) TryDuration(path string) (time.Duration, error) {
+	v, err := l.Toml.try(path, String)
+	if err != nil {
+		return 0, err
+	}
+	if l.Policy&CoerceString == 0 {
+		return 0, NotSupported
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(v.String()))
+	if err != nil {
+		return 0, err
+	}
+	l.warn(path, v, "Duration")
+	return d, nil
+}
+
+func (l Lenient) GetString(path, def string) string {
+	return l.getter().getString(path, def)
+}
+
+func (l Lenient) GetInt(path string, def int64) int64 {
+	return l.getter().getInt(path, def)
+}
+
+func (l Lenient) GetFloat(path string, def float64) float64 {
+	return l.getter().getFloat(path, def)
+}
+
+func (l Lenient) GetBool(path string, def bool) bool {
+	return l.getter().getBool(path, def)
+}
+
+func (l Lenient) GetTime(path string, def time.Time) time.Time {
+	return l.getter().getTime(path, def)
+}
+
+func (l Lenient) GetStringSlice(path string, def []string) []string {
+	return l.getter().getStringSlice(path, def)
+}
+
+func (l Lenient) GetIntSlice(path string, def []int64) []int64 {
+	return l.getter().getIntSlice(path, def)
+}
+
+func (l Lenient) GetDuration(path string, def time.Duration) time.Duration {
+	d, err := l.TryDuration(path)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+func (l Lenient) MustString(path string) string {
+	return l.getter().mustString(path)
+}
+
+func (l Lenient) MustInt(path string) int64 {
+	return l.getter().mustInt(path)
+}
+
+func (l Lenient) MustFloat(path string) float64 {
+	return l.getter().mustFloat(path)
+}
+
+func (l Lenient) MustBool(path string) bool {
+	return l.getter().mustBool(path)
+}
+
+func (l Lenient) MustTime(path string) time.Time {
+	return l.getter().mustTime(path)
+}
+
+func (l Lenient) MustStringSlice(path string) []string {
+	return l.getter().mustStringSlice(path)
+}
+
+func (l Lenient) MustIntSlice(path string) []int64 {
+	return l.getter().mustIntSlice(path)
+}
+
+func (l Lenient) MustDuration(path string) time.Duration {
+	d, err := l.TryDuration(path)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+/**
+Apply 同 Toml.Apply, 但是按照 Policy 转换类型, 包括 String 转换为 time.Duration.
+*/
+func (l Lenient) Apply(dst interface{}) (count int) {
+	vv, ok := dst.(reflect.Value)
+	if ok {
+		vv = reflect.Indirect(vv)
+	} else {
+		vv = reflect.Indirect(reflect.ValueOf(dst))
+	}
+	return l.Toml.apply(vv, &l, "")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
@@ -0,0 +1,134 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+	"time"
+)
+
+const legacyDat = `
+port = "8080"
+ratio = 2
+debug = " true "
+since = "2014-01-02T15:04:05Z"
+timeout = "1m30s"
+hosts = "alpha"
+ports = ["8001", "8002"]
+name = "x"
+
+[[servers]]
+port = "9090"
+`
+
+func TestLenient(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte(legacyDat))
+	wt.Nil(err)
+
+	// 默认不转换
+	_, err = tm.TryInt("port")
+	wt.Equal(err, &KindError{"port", Integer, String})
+	_, err = Lenient{Toml: tm}.TryInt("port")
+	wt.Equal(err, &KindError{"port", Integer, String})
+
+	var warns []string
+	lt := Lenient{Toml: tm, Policy: CoerceAll, Warn: func(c Coercion) {
+		warns = append(warns, c.String())
+	}}
+
+	wt.Equal(lt.MustInt("port"), int64(8080))
+	wt.Equal(lt.MustInt("servers[0].port"), int64(9090))
+	wt.Equal(lt.MustFloat("ratio"), 2.0)
+	wt.Equal(lt.MustFloat("port"), 8080.0)
+	wt.Equal(lt.MustBool("debug"), true)
+	wt.Equal(lt.MustTime("since"), time.Date(2014, 1, 2, 15, 4, 5, 0, time.UTC))
+	wt.Equal(lt.MustDuration("timeout"), 90*time.Second)
+	wt.Equal(lt.MustStringSlice("hosts"), []string{"alpha"})
+	wt.Equal(lt.GetIntSlice("ports", nil), []int64{8001, 8002})
+	wt.Equal(lt.MustString("name"), "x")
+	wt.Equal(lt.GetInt("name", 1), int64(1))
+	wt.Equal(lt.GetInt("nothing", 1), int64(1))
+	wt.Equal(lt.GetDuration("port", time.Second), time.Second)
+
+	wt.Equal(warns[0], `toml: port: coerce String "8080" to Integer`)
+	wt.Equal(warns[6], `toml: timeout: coerce String "1m30s" to Duration`)
+	wt.Equal(len(warns), 9)
+	wt.Equal(warns[8], `toml: ports: coerce StringArray "[\"8001\", \"8002\"]" to IntegerArray`)
+	wt.Equal(lt.MustIntSlice("ports"), []int64{8001, 8002})
+	wt.Equal(len(warns), 10)
+
+	// 只允许部分转换
+	lt = Lenient{Toml: tm, Policy: CoerceFloat}
+	wt.Equal(lt.GetFloat("ratio", 0), 2.0)
+	wt.Equal(lt.GetFloat("port", 0), 0.0)
+	wt.Equal(lt.GetStringSlice("hosts", nil), []string(nil))
+	_, err = lt.TryDuration("timeout")
+	wt.Equal(err, NotSupported)
+}
+
+const legacyApplyDat = `
+port = "1"
+Port = "8080"
+Ratio = 2
+Debug = " true "
+Since = "2014-01-02T15:04:05Z"
+Timeout = "1m30s"
+Hosts = "alpha"
+Ports = ["8001", "8002"]
+Name = "x"
+`
+
+type legacyConfig struct {
+	port    int
+	Port    int
+	Ratio   float64
+	Debug   bool
+	Since   time.Time
+	Timeout time.Duration
+	Hosts   []string
+	Ports   []uint16
+	Name    int
+}
+
+func TestLenientApply(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte(legacyApplyDat))
+	wt.Nil(err)
+
+	var cfg legacyConfig
+	wt.Equal(tm.Apply(&cfg), 0)
+
+	var paths []string
+	cfg = legacyConfig{Hosts: make([]string, 0, 1), Ports: make([]uint16, 2)}
+	lt := Lenient{Toml: tm, Policy: CoerceAll, Warn: func(c Coercion) {
+		paths = append(paths, c.Path+" "+c.To)
+	}}
+	wt.Equal(lt.Apply(&cfg), 8)
+	wt.Equal(cfg.Port, 8080)
+	wt.Equal(cfg.Ratio, 2.0)
+	wt.Equal(cfg.Debug, true)
+	wt.Equal(cfg.Since.Year(), 2014)
+	wt.Equal(cfg.Timeout, 90*time.Second)
+	wt.Equal(cfg.Hosts, []string{"alpha"})
+	wt.Equal(cfg.Ports, []uint16{8001, 8002})
+	wt.Equal(cfg.Name, 0)
+
+	wt.Equal(paths, []string{
+		"Port Integer", "Ratio Float", "Debug Boolean", "Since Datetime", "Timeout Duration",
+		"Hosts Array", "Ports[0] Integer", "Ports[1] Integer",
+	})
+
+	// 单个值转换为数组时, 元素赋值成功后才警告
+	var one struct {
+		Port  []int
+		Name  []int
+		Empty []int
+	}
+	one.Port, one.Name, one.Empty = make([]int, 0, 1), make([]int, 0, 1), make([]int, 0)
+	lt.Toml, err = Parse([]byte("Port = \"80\"\nName = \"x\"\nEmpty = 1\n"))
+	wt.Nil(err)
+	paths = paths[:0]
+	wt.Equal(lt.Apply(&one), 1)
+	wt.Equal(one.Port, []int{80})
+	wt.Equal(paths, []string{"Port[0] Integer", "Port Array"})
+}
@@ -0,0 +1,156 @@
+package toml
+
+import (
+	"sort"
+	"strings"
+)
+
+/**
+ToMap 把 Toml 转换为嵌套的 map, 不含注释. 对应关系:
+	TableName      map[string]interface{}
+	ArrayOfTables  []map[string]interface{}
+	Array, typeArray []interface{}
+	Datetime       time.Time
+	其他值          string, int64, float64, bool
+没有 TableName 的上级 Table 也被转换为 map, 比如 Fetch 的结果.
+*/
+func (p Toml) ToMap() map[string]interface{} {
+	return p.toMap((*Value).toInterface)
+}
+
+// toMap 把扁平的 Toml 转换为嵌套的 map, 值由 conv 转换.
+func (p Toml) toMap(conv func(*Value) interface{}) map[string]interface{} {
+	root := map[string]interface{}{}
+	for _, k := range p.validKeys() {
+		it := p[k.key]
+		segs := strings.Split(k.key, ".")
+		m := root
+		for _, s := range segs[:len(segs)-1] {
+			sub, ok := m[s].(map[string]interface{})
+			if !ok {
+				sub = map[string]interface{}{}
+				m[s] = sub
+			}
+			m = sub
+		}
+
+		key := segs[len(segs)-1]
+		if it.kind == TableName {
+			if _, ok := m[key].(map[string]interface{}); !ok {
+				m[key] = map[string]interface{}{}
+			}
+			continue
+		}
+		m[key] = conv(it.Value)
+	}
+	return root
+}
+
+// toInterface 返回 Value 存储的数据, 数组转换为 []interface{}, ArrayOfTables 转换为 []map.
+func (p *Value) toInterface() interface{} {
+	if p != nil && p.kind == Array && p.v == nil {
+		return []interface{}{}
+	}
+	if !p.IsValid() {
+		return nil
+	}
+	switch v := p.v.(type) {
+	case []*Value:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = e.toInterface()
+		}
+		return a
+	case TomlArray:
+		a := make([]map[string]interface{}, len(v))
+		for i, tm := range v {
+			a[i] = tm.ToMap()
+		}
+		return a
+	}
+	return p.v
+}
+
+/**
+FromMap 是 ToMap 的逆操作, 由嵌套的 map 建立 Toml.
+map[string]interface{} 转换为 TableName, []map[string]interface{}
+以及元素都是 map[string]interface{} 的非空 []interface{} 转换为 ArrayOfTables,
+其他值的转换规则与 Set 相同, 数组必须满足 Value.Add 的要求.
+为使结果确定, 同一层的 key 按名称次序加入. key 不能含有 ".", 值不能是 nil.
+*/
+func FromMap(m map[string]interface{}) (Toml, error) {
+	tm := New()
+	if err := tm.fromMap("", m); err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+func (p Toml) fromMap(path string, m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sub := quoteKey(k)
+		if path != "" {
+			sub = path + "." + sub
+		}
+
+		var err error
+		switch v := m[k].(type) {
+		case nil:
+			err = NotSupported
+		case map[string]interface{}:
+			if err = p.makeTable(sub); err == nil {
+				err = p.fromMap(sub, v)
+			}
+		case []map[string]interface{}:
+			err = p.setTables(sub, v, Toml.fromMap)
+		case []interface{}:
+			if ms, ok := asTables(v); ok {
+				err = p.setTables(sub, ms, Toml.fromMap)
+			} else {
+				err = p.Set(sub, v)
+			}
+		default:
+			err = p.Set(sub, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setTables 在 path 建立 ArrayOfTables, 由 fill 填充每个 Table.
+func (p Toml) setTables(path string, ms []map[string]interface{},
+	fill func(Toml, string, map[string]interface{}) error) error {
+
+	segs, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	tm, key, undo, err := p.makeParent(segs)
+	if err != nil {
+		return err
+	}
+	if _, ok := tm[key]; ok || tm.hasChildren(key) || len(segs[len(segs)-1].idx) != 0 {
+		undo()
+		return NotSupported
+	}
+
+	it := GenItem(ArrayOfTables)
+	for _, m := range ms {
+		elem := New()
+		if err = fill(elem, "", m); err != nil {
+			undo()
+			return err
+		}
+		it.AddTable(elem)
+	}
+	tm[key] = it
+	return nil
+}
@@ -0,0 +1,81 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+	"time"
+)
+
+func TestTomlToMap(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	m := tm.ToMap()
+	wt.Equal(m["title"], "TOML Example")
+
+	owner := m["owner"].(map[string]interface{})
+	wt.Equal(owner["dob"], time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC))
+
+	database := m["database"].(map[string]interface{})
+	wt.Equal(database["ports"], []interface{}{int64(8001), int64(8001), int64(8002)})
+	wt.Equal(database["enabled"], true)
+
+	alpha := m["servers"].(map[string]interface{})["alpha"].(map[string]interface{})
+	wt.Equal(alpha["ip"], "10.0.0.1")
+
+	clients := m["clients"].(map[string]interface{})
+	wt.Equal(clients["data"], []interface{}{
+		[]interface{}{"gamma", "delta"},
+		[]interface{}{int64(1), int64(2)},
+	})
+
+	products := m["products"].([]map[string]interface{})
+	wt.Equal(len(products), 2)
+	wt.Equal(products[1]["color"], "gray")
+
+	fruit := m["fruit"].([]map[string]interface{})
+	wt.Equal(fruit[0]["physical"], map[string]interface{}{"color": "red", "shape": "round"})
+	wt.Equal(fruit[0]["variety"], []map[string]interface{}{
+		{"name": "red delicious"},
+		{"name": "granny smith"},
+	})
+
+	// 没有 TableName 的 Table
+	wt.Equal(tm.Fetch("servers").ToMap()["beta"].(map[string]interface{})["country"], "中国")
+}
+
+func TestFromMap(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	ntm, err := FromMap(tm.ToMap())
+	wt.Nil(err)
+	wt.True(Equal(tm, ntm, IgnoreComments, IgnoreOrder), ntm.String())
+
+	ntm, err = FromMap(map[string]interface{}{
+		"name":  "app",
+		"ports": []int{80, 443},
+		"log":   map[string]interface{}{"level": "info"},
+		"users": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{"name": "b"},
+		},
+	})
+	wt.Nil(err)
+	wt.Equal(ntm["log"].Kind(), TableName)
+	wt.Equal(ntm["ports"].IntArray(), []int64{80, 443})
+	wt.Equal(ntm.Get("users[1].name").String(), "b")
+
+	tm, err = Parse([]byte(ntm.String()))
+	wt.Nil(err)
+	wt.True(Equal(tm, ntm, IgnoreComments, IgnoreOrder))
+
+	_, err = FromMap(map[string]interface{}{"a": nil})
+	wt.Equal(err, NotSupported)
+	_, err = FromMap(map[string]interface{}{"a": []interface{}{1, "a"}})
+	wt.Equal(err, NotSupported)
+	_, err = FromMap(map[string]interface{}{"a.b": 1})
+	wt.Equal(err, InvalidPath)
+}
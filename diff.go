@@ -0,0 +1,166 @@
+package toml
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ChangeKind 表示 Change 的类型.
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota + 1
+	Removed
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Modified:
+		return "Modified"
+	}
+	return "ChangeKind." + strconv.Itoa(int(k))
+}
+
+/**
+Change 描述两个 Toml 之间的一处差异.
+Path 是访问路径, 含有 ArrayOfTables 的下标, 可以直接用于 Lookup, Set 和 Delete.
+Added 时 Old 为 nil, Removed 时 New 为 nil.
+ArrayOfTables 的元素整个增加或删除时, Path 以下标结尾, Old 和 New 都为 nil,
+随后是该元素中各个元素的 Change.
+*/
+type Change struct {
+	Kind        ChangeKind
+	Path        string
+	Old         *Value
+	New         *Value
+	KindChanged bool // Modified 时 Kind 是否改变
+}
+
+// Changes 是 Diff 的结果, String() 以类似 unified diff 的格式输出.
+type Changes []Change
+
+/**
+Diff 比较 a 和 b 的内容, 返回从 a 到 b 的全部差异, 不比较注释和次序.
+TableName 和 Key 按完全路径比较, ArrayOfTables 按下标逐个比较,
+数组被当作一个值比较.
+*/
+func Diff(a, b Toml) Changes {
+	var cs Changes
+	cs.diff(a, b, nil)
+	return cs
+}
+
+// keyPath 返回 prefix 之后加上 key 各节的路径, 用于 JoinPath.
+func keyPath(prefix []string, key string) []string {
+	return append(prefix[:len(prefix):len(prefix)], strings.Split(key, ".")...)
+}
+
+func (cs *Changes) diff(a, b Toml, prefix []string) {
+	for _, k := range a.validKeys() {
+		segs := keyPath(prefix, k.key)
+		path := JoinPath(segs)
+		ia := a[k.key]
+		ib, ok := b[k.key]
+
+		if !ok || !ib.IsValid() {
+			*cs = append(*cs, Change{Kind: Removed, Path: path, Old: ia.Value})
+			continue
+		}
+
+		if ia.kind != ib.kind {
+			*cs = append(*cs, Change{Modified, path, ia.Value, ib.Value, true})
+			continue
+		}
+
+		switch ia.kind {
+		case TableName:
+		case ArrayOfTables:
+			ta, tb := ia.TomlArray(), ib.TomlArray()
+			for i := 0; i < len(ta) || i < len(tb); i++ {
+				isegs := subPath(segs, "["+strconv.Itoa(i)+"]")
+				switch {
+				case i >= len(tb):
+					*cs = append(*cs, Change{Kind: Removed, Path: JoinPath(isegs)})
+					cs.diff(ta[i], nil, isegs)
+				case i >= len(ta):
+					*cs = append(*cs, Change{Kind: Added, Path: JoinPath(isegs)})
+					cs.diff(nil, tb[i], isegs)
+				default:
+					cs.diff(ta[i], tb[i], isegs)
+				}
+			}
+		default:
+			if !ia.Equal(ib.Value, IgnoreComments) {
+				*cs = append(*cs, Change{Modified, path, ia.Value, ib.Value, false})
+			}
+		}
+	}
+
+	for _, k := range b.validKeys() {
+		if it, ok := a[k.key]; ok && it.IsValid() {
+			continue
+		}
+		*cs = append(*cs, Change{Kind: Added, Path: JoinPath(keyPath(prefix, k.key)), New: b[k.key].Value})
+	}
+}
+
+// diffString 返回 Change 中 Value 的单行表示, 不含注释.
+func diffString(path string, v *Value) string {
+	if v == nil {
+		return path
+	}
+	switch v.kind {
+	case TableName:
+		return "[" + path + "]"
+	case ArrayOfTables:
+		return "[[" + path + "]] # " + strconv.Itoa(v.Len()) + " tables"
+	}
+	return path + " = " + v.plain()
+}
+
+func (p *Value) plain() string {
+	if !isArrayKind(p.kind) {
+		return p.string("", 1)
+	}
+	a, _ := p.v.([]*Value)
+	ss := make([]string, len(a))
+	for i, v := range a {
+		ss[i] = v.plain()
+	}
+	return "[" + strings.Join(ss, ", ") + "]"
+}
+
+/**
+String 返回 Change 的文本表示, 每行以 "-" 或 "+" 开头, 如:
+	- database.port = 8080
+	+ database.port = "8080" # Integer -> String
+*/
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return "+ " + diffString(c.Path, c.New)
+	case Removed:
+		return "- " + diffString(c.Path, c.Old)
+	case Modified:
+		s := "- " + diffString(c.Path, c.Old) + "\n+ " + diffString(c.Path, c.New)
+		if c.KindChanged {
+			s += " # " + c.Old.kind.String() + " -> " + c.New.kind.String()
+		}
+		return s
+	}
+	return ""
+}
+
+// String 以类似 unified diff 的格式输出全部差异, 每行一个 Key, Modified 占两行.
+func (cs Changes) String() string {
+	s := ""
+	for _, c := range cs {
+		s += c.String() + "\n"
+	}
+	return s
+}
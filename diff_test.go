@@ -0,0 +1,57 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	wt := want.T(t)
+	a, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	wt.Equal(len(Diff(a, a.Clone())), 0)
+
+	b := a.Clone()
+	b["title"].SetComment("comments are ignored")
+	wt.Nil(b.Set("database.ports[2]", 8003))
+	wt.Nil(b.Delete("servers.beta.country"))
+	wt.Nil(b.Set("servers.gamma.ip", "10.0.0.3"))
+	wt.Nil(b.Delete("database.enabled"))
+	wt.Nil(b.Set("database.enabled", "yes"))
+	wt.Nil(b.Set("products[1].color", "black"))
+	wt.Nil(b.Set("products[2].name", "Saw"))
+	wt.Nil(b.Delete("fruit[0].variety[1]"))
+
+	cs := Diff(a, b)
+	wt.Equal(cs.String(), `- database.ports = [8001, 8001, 8002]
++ database.ports = [8001, 8001, 8003]
+- database.enabled = true
++ database.enabled = "yes" # Boolean -> String
+- servers.beta.country = "中国"
+- products[1].color = "gray"
++ products[1].color = "black"
++ products[2]
++ products[2].name = "Saw"
+- fruit[0].variety[1]
+- fruit[0].variety[1].name = "granny smith"
++ [servers.gamma]
++ servers.gamma.ip = "10.0.0.3"
+`)
+
+	c := cs[1]
+	wt.Equal(c.Kind, Modified)
+	wt.Equal(c.Path, "database.enabled")
+	wt.True(c.KindChanged)
+	wt.Equal(c.Old.Boolean(), true)
+	wt.Equal(c.New.String(), "yes")
+
+	for _, c := range cs {
+		if c.Kind == Removed {
+			continue
+		}
+		_, ok := b.Lookup(c.Path)
+		_, tok := b.LookupTable(c.Path)
+		wt.True(ok || tok, c.Path)
+	}
+}
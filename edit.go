@@ -0,0 +1,407 @@
+package toml
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 合法的 Key 或 TableName 的一节, 保证 String() 的输出可以被再次解析.
+func validKey(key string) bool {
+	return key != "" && strings.IndexAny(key, " \t\r\n\x1E=#.[]\"") == -1
+}
+
+/**
+ValueOf 由 x 建立新的 *Value, x 支持的类型与 Toml.Set 相同, 包括 slice.
+与 NewValue 加 Set 不同, ValueOf 可以建立数组, 包括空数组.
+*/
+func ValueOf(x interface{}) (*Value, error) {
+	return toValue(x)
+}
+
+/**
+toValue 把 x 转换为 *Value. x 可以是 Value.Set 支持的类型, *Value, Item,
+或者元素为这些类型的 slice 和 array, 它们会通过 Value.Add 转换为 Array 或 typeArray.
+*Value 和 Item 总是被复制, 返回值不与 x 共享.
+*/
+func toValue(x interface{}) (*Value, error) {
+	switch v := x.(type) {
+	case *Value:
+		if !v.IsValue() {
+			return nil, NotSupported
+		}
+		return v.Clone(), nil
+	case Item:
+		if !v.IsValue() {
+			return nil, NotSupported
+		}
+		return v.Value.Clone(), nil
+	case time.Time:
+		nv := NewValue(Datetime)
+		return nv, nv.Set(v)
+	}
+
+	rv := reflect.ValueOf(x)
+	if !rv.IsValid() {
+		return nil, NotSupported
+	}
+
+	nv := NewValue(InvalidKind)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		nv.kind = Array
+		nv.v = []*Value{}
+		for i := 0; i < rv.Len(); i++ {
+			ev, err := toValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			if err = nv.Add(ev); err != nil {
+				return nil, err
+			}
+		}
+		return nv, nil
+	case reflect.String:
+		return nv, nv.Set(rv.String())
+	case reflect.Bool:
+		return nv, nv.Set(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return nv, nv.Set(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return nv, nv.Set(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return nv, nv.Set(rv.Float())
+	}
+	return nil, NotSupported
+}
+
+// 数组 kind 之间的判断, 空数组的 kind 为 Array.
+func isArrayKind(k Kind) bool {
+	return k >= StringArray && k <= Array
+}
+
+/**
+replace 用 nv 的值替换 p 的值, 保留 p 的注释和次序.
+要求两者的 Kind 相同, 空数组可以替换任何数组.
+*/
+func (p *Value) replace(nv *Value) error {
+	if p.kind != nv.kind &&
+		(!isArrayKind(p.kind) || nv.kind != Array || nv.Len() != 0) {
+		return NotSupported
+	}
+	p.v = nv.v
+	p.idx = counter(p.idx)
+	return nil
+}
+
+/**
+Set 根据访问路径设置值, 访问路径的格式参见 Lookup.
+x 的类型范围同 Value.Set, 也可以是 *Value, Item 以及由这些类型组成的 slice 和 array.
+slice 和 array 会通过 Value.Add 转换为 typeArray 或 Array, 因此必须满足 Add 的要求.
+
+	tm.Set("servers.alpha.ip", "10.0.0.1")  // 自动建立 [servers] 和 [servers.alpha]
+	tm.Set("database.ports", []int{8001, 8002})
+	tm.Set("database.ports[-1]", 8003)      // 替换数组元素
+	tm.Set("database.ports[2]", 8004)       // 下标等于数组长度时追加元素
+	tm.Set("products[0].name", "Hammer")    // ArrayOfTables 的元素
+	tm.Set("products[1].name", "Nail")      // 下标等于 ArrayOfTables 长度时追加 Table
+
+规则:
+	路径中缺少的 TableName 会按路径的次序自动建立.
+	已经存在的值只能被相同 Kind 的值替换, 替换后保留注释.
+	TableName 和 ArrayOfTables 不能被替换为值, 包括未声明的 TableName, 值也不能被当作 TableName 使用.
+失败时返回 InvalidPath, NotFound, OutOfRange 或 NotSupported, tm 保持不变.
+*/
+func (p Toml) Set(path string, x interface{}) error {
+	segs, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+
+	nv, err := toValue(x)
+	if err != nil {
+		return err
+	}
+
+	tm, key, undo, err := p.makeParent(segs)
+	if err != nil {
+		return err
+	}
+	if err = tm.setLast(key, segs[len(segs)-1], nv); err != nil {
+		// 失败时不留下自动建立的上级
+		undo()
+	}
+	return err
+}
+
+// setLast 把 nv 写入 key, seg 是访问路径的最后一节, 可以带有数组下标.
+func (p Toml) setLast(key string, seg pathSeg, nv *Value) error {
+	it, ok := p[key]
+	ok = ok && it.IsValid()
+
+	if len(seg.idx) == 0 {
+		if !ok {
+			// 未声明的 TableName, 比如只有 [a.b] 时的 a
+			if p.hasChildren(key) {
+				return NotSupported
+			}
+			it = GenItem(0)
+			it.kind, it.v = nv.kind, nv.v
+			p[key] = it
+			return nil
+		}
+		if it.kind >= TableName {
+			return NotSupported
+		}
+		return it.replace(nv)
+	}
+
+	// 数组元素
+	if !ok {
+		return NotFound
+	}
+	if !isArrayKind(it.kind) {
+		return NotSupported
+	}
+
+	v := it.Value
+	last := len(seg.idx) - 1
+	for j, n := range seg.idx {
+		size := v.Len()
+		if j == last && n == size {
+			return v.Add(nv)
+		}
+
+		n, ok = normIndex(n, size)
+		if !ok {
+			return OutOfRange
+		}
+
+		if j != last {
+			v = v.Index(n)
+			continue
+		}
+
+		// 保持 typeArray 的一致性
+		ev := v.Index(n)
+		if v.kind != Array && ev.kind != nv.kind {
+			return NotSupported
+		}
+		if v.kind == Array && isArrayKind(ev.kind) != isArrayKind(nv.kind) {
+			return NotSupported
+		}
+		ev.kind = nv.kind
+		return ev.replace(nv)
+	}
+	return InternalError
+}
+
+/**
+makeParent 沿 segs 逐级建立缺少的 TableName 以及 ArrayOfTables 的元素,
+返回最后一节所在的 Toml 和它在其中的 key.
+ArrayOfTables 的下标等于其长度时追加新的 Table.
+失败时撤销已经建立的元素, 成功时返回的 undo 用于调用者随后失败时撤销.
+*/
+func (p Toml) makeParent(segs []pathSeg) (tm Toml, key string, undo func(), err error) {
+	var undos []func()
+	undoAll := func() {
+		for i := len(undos) - 1; i >= 0; i-- {
+			undos[i]()
+		}
+	}
+	defer func() {
+		if err != nil {
+			undoAll()
+		}
+	}()
+
+	tm = p
+	prefix := ""
+	max := len(segs) - 1
+
+	for i, seg := range segs {
+		if !validKey(seg.key) {
+			return nil, "", nil, InvalidPath
+		}
+
+		key = seg.key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if i == max {
+			return tm, key, undoAll, nil
+		}
+
+		it, ok := tm[key]
+		ok = ok && it.IsValid()
+
+		if len(seg.idx) == 0 {
+			if !ok {
+				tm[key] = GenItem(TableName)
+				undos = append(undos, deleter(tm, key))
+			} else if it.kind != TableName {
+				return nil, "", nil, NotSupported
+			}
+			prefix = key
+			continue
+		}
+
+		if len(seg.idx) != 1 {
+			return nil, "", nil, InvalidPath
+		}
+
+		if !ok {
+			if seg.idx[0] != 0 {
+				return nil, "", nil, OutOfRange
+			}
+			it = GenItem(ArrayOfTables)
+			tm[key] = it
+			undos = append(undos, deleter(tm, key))
+		} else if it.kind != ArrayOfTables {
+			return nil, "", nil, NotSupported
+		}
+
+		if seg.idx[0] == it.Len() {
+			old := it.v
+			if err = it.AddTable(New()); err != nil {
+				return nil, "", nil, err
+			}
+			undos = append(undos, func() { it.v = old })
+		}
+
+		n, ok := normIndex(seg.idx[0], it.Len())
+		if !ok {
+			return nil, "", nil, OutOfRange
+		}
+		tm, prefix = it.Table(n), ""
+	}
+	return tm, key, undoAll, nil
+}
+
+func deleter(tm Toml, key string) func() {
+	return func() { delete(tm, key) }
+}
+
+// hasChildren 返回是否存在 key 下属的有效元素, 用于判断未声明的 TableName.
+func (p Toml) hasChildren(key string) bool {
+	prefix := key + "."
+	for k, it := range p {
+		if strings.HasPrefix(k, prefix) && it.IsValid() {
+			return true
+		}
+	}
+	return false
+}
+
+// remove 删除 key 及 key 下属的全部元素.
+func (p Toml) remove(key string) {
+	delete(p, key)
+	prefix := key + "."
+	for k := range p {
+		if strings.HasPrefix(k, prefix) {
+			delete(p, k)
+		}
+	}
+}
+
+/**
+Delete 删除访问路径对应的元素, 访问路径的格式参见 Lookup.
+
+	tm.Delete("servers")            // 删除 [servers] 及所有 "servers." 开头的元素, [servers] 可以未声明
+	tm.Delete("servers.alpha.ip")   // 删除 Key-Value
+	tm.Delete("products[0]")        // 删除 ArrayOfTables 的元素, 全部删除后 products 也被删除
+	tm.Delete("database.ports[-1]") // 删除数组元素
+
+路径不存在时返回 NotFound.
+*/
+func (p Toml) Delete(path string) error {
+	segs, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+
+	t, err := p.lookup(segs)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case t.elem != nil:
+		aot := t.it.TomlArray()
+		if len(aot) == 1 {
+			delete(t.tm, t.key)
+			return nil
+		}
+		t.it.v = append(aot[:t.idx:t.idx], aot[t.idx+1:]...)
+	case t.parent != nil:
+		a := t.parent.v.([]*Value)
+		t.parent.v = append(a[:t.idx:t.idx], a[t.idx+1:]...)
+	default:
+		t.tm.remove(t.key)
+	}
+	return nil
+}
+
+/**
+Move 把 from 对应的 Key-Value, TableName 或 ArrayOfTables 连同其下属元素重命名为 to.
+被移动的元素保留原有的注释和次序, to 中缺少的上级 TableName 会自动建立.
+
+	tm.Move("servers.alpha", "servers.gamma")
+	tm.Move("title", "owner.title")
+	tm.Move("products[0].sku", "products[0].id")
+
+from 可以是未声明的 TableName, 比如只有 [a.b] 时的 a.
+from 和 to 都不能以下标结尾, to 已经存在时返回 Redeclared, 包括 to 之下已有元素.
+*/
+func (p Toml) Move(from, to string) error {
+	fsegs, err := splitPath(from)
+	if err != nil {
+		return err
+	}
+	tsegs, err := splitPath(to)
+	if err != nil {
+		return err
+	}
+
+	if len(tsegs[len(tsegs)-1].idx) != 0 {
+		return NotSupported
+	}
+
+	t, err := p.lookup(fsegs)
+	if err != nil {
+		return err
+	}
+	if t.elem != nil || t.parent != nil {
+		return NotSupported
+	}
+
+	// 不能移动到自身之下
+	if len(tsegs) >= len(fsegs) && reflect.DeepEqual(tsegs[:len(fsegs)], fsegs) {
+		return NotSupported
+	}
+
+	if _, err = p.lookup(tsegs); err == nil {
+		return Redeclared
+	}
+
+	tm, key, _, err := p.makeParent(tsegs)
+	if err != nil {
+		return err
+	}
+
+	moved := Toml{}
+	prefix := t.key + "."
+	for k, it := range t.tm {
+		if k == t.key || strings.HasPrefix(k, prefix) {
+			moved[key+k[len(t.key):]] = it
+		}
+	}
+
+	t.tm.remove(t.key)
+	for k, it := range moved {
+		tm[k] = it
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+	"time"
+)
+
+func TestTomlSet(t *testing.T) {
+	wt := want.T(t)
+	tm := New()
+
+	wt.Nil(tm.Set("title", "TOML"))
+	wt.Nil(tm.Set("servers.alpha.ip", "10.0.0.1"))
+	wt.Nil(tm.Set("servers.alpha.port", 8080))
+	wt.Nil(tm.Set("servers.alpha.dob", time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)))
+	wt.Nil(tm.Set("database.ports", []int{8001, 8001}))
+	wt.Nil(tm.Set("database.ports[2]", 8002))
+	wt.Nil(tm.Set("database.ports[0]", 8000))
+	wt.Nil(tm.Set("clients.data", []interface{}{[]string{"gamma"}, []int{1, 2}}))
+
+	wt.Equal(tm["servers"].Kind(), TableName)
+	wt.Equal(tm["servers.alpha"].Kind(), TableName)
+	wt.True(tm["servers"].Id() < tm["servers.alpha"].Id())
+	wt.True(tm["servers.alpha"].Id() < tm["servers.alpha.ip"].Id())
+	wt.Equal(tm["database.ports"].Kind(), IntegerArray)
+	wt.Equal(tm["database.ports"].IntArray(), []int64{8000, 8001, 8002})
+	wt.Equal(tm["clients.data"].Kind(), Array)
+	wt.Equal(tm["servers.alpha.dob"].String(), "1979-05-27T07:32:00Z")
+
+	// 保留注释, 要求 Kind 相同
+	tm["title"].SetComment("the title")
+	wt.Nil(tm.Set("title", "TOML Example"))
+	wt.Equal(tm["title"].String(), "TOML Example")
+	wt.Equal(tm["title"].Comment(), "# the title")
+	wt.Equal(tm.Set("title", 1), NotSupported)
+
+	// 不能用值替换 TableName, 值也不能当作 TableName
+	wt.Equal(tm.Set("servers", 1), NotSupported)
+	wt.Equal(tm.Set("title.sub", 1), NotSupported)
+
+	// typeArray 保持一致
+	wt.Equal(tm.Set("database.ports[0]", "8000"), NotSupported)
+	wt.Equal(tm.Set("database.ports[4]", 8004), OutOfRange)
+	wt.Equal(tm.Set("database.ports", []interface{}{1, "a"}), NotSupported)
+	wt.Equal(tm.Set("database.nothing[0]", 1), NotFound)
+
+	// ArrayOfTables
+	wt.Nil(tm.Set("products[0].name", "Hammer"))
+	wt.Nil(tm.Set("products[1].name", "Nail"))
+	wt.Nil(tm.Set("products[-1].sku", 284758393))
+	wt.Equal(tm.Set("products[3].name", "Saw"), OutOfRange)
+	wt.Equal(tm.Set("products.name", "Saw"), NotSupported)
+	wt.Equal(tm.Set("products[0]", 1), NotSupported)
+
+	wt.Equal(tm["products"].Len(), 2)
+	wt.Equal(tm.Get("products[1].sku").Integer(), 284758393)
+
+	wt.Equal(tm.Set("bad key", 1), InvalidPath)
+	wt.Equal(tm.Set(`"a.b"`, 1), InvalidPath)
+
+	// 输出后可以被再次解析
+	ntm, err := Parse([]byte(tm.String()))
+	wt.Nil(err, tm.String())
+	wt.Equal(ntm.Get("servers.alpha.port").Integer(), 8080)
+	wt.Equal(ntm.Get("database.ports").IntArray(), []int64{8000, 8001, 8002})
+	wt.Equal(ntm.Get("products[1].name").String(), "Nail")
+	wt.Equal(ntm.Get("clients.data[1][1]").Integer(), 2)
+
+	// 不与参数共享 Value
+	src, err := ValueOf([]int{1, 2})
+	wt.Nil(err)
+	wt.Nil(tm.Set("database.ports", src))
+	wt.Nil(tm.Set("products[0].ports", Item{src}))
+	wt.Nil(src.Add(3))
+	wt.Nil(src.Index(0).Set(0))
+	wt.Equal(tm["database.ports"].IntArray(), []int64{1, 2})
+	wt.Equal(tm.Get("products[0].ports").IntArray(), []int64{1, 2})
+}
+
+func TestTomlSetImplicit(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte("[a.b]\nc = 1\n"))
+	wt.Nil(err)
+	keys, source := tm.Keys(), tm.String()
+
+	// 未声明的 TableName 也不能被替换为值
+	wt.Equal(tm.Set("a", 1), NotSupported)
+	wt.Equal(tm.Get("a.b.c").Integer(), 1)
+
+	// 失败时不留下自动建立的上级
+	wt.Equal(tm.Set("a.b.c.d", 1), NotSupported)
+	wt.Equal(tm.Set("x.y[0]", 1), NotFound)
+	wt.Equal(tm.Set("p[0].q.r", []interface{}{1, "a"}), NotSupported)
+	wt.Nil(tm.Set("p[0].q", 1))
+	wt.Equal(tm.Set("p[1].q[0]", 1), NotFound)
+	wt.Equal(tm["p"].Len(), 1)
+	wt.Nil(tm.Delete("p"))
+	wt.Equal(tm.Keys(), keys)
+	wt.Equal(tm.String(), source)
+
+	wt.Nil(tm.Set("a.d", 2))
+	wt.Equal(tm.Keys(), append(keys, "a", "a.d"))
+}
+
+func TestTomlDelete(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	wt.Nil(tm.Delete("servers"))
+	for _, key := range []string{"servers", "servers.alpha", "servers.alpha.ip", "servers.beta.country"} {
+		_, ok := tm[key]
+		wt.False(ok, key)
+	}
+
+	wt.Nil(tm.Delete("owner.dob"))
+	wt.False(tm.Get("owner.dob").IsValid())
+	wt.True(tm.Get("owner.name").IsValid())
+
+	wt.Nil(tm.Delete("database.ports[0]"))
+	wt.Equal(tm.Get("database.ports").IntArray(), []int64{8001, 8002})
+
+	wt.Nil(tm.Delete("fruit[0].variety[-1]"))
+	wt.Equal(tm.Get("fruit[0].variety").Len(), 1)
+	wt.Nil(tm.Delete("fruit[0]"))
+	wt.Equal(tm.Get("fruit").Len(), 1)
+	wt.Equal(tm.Get("fruit[0].name").String(), "banana")
+	wt.Nil(tm.Delete("fruit[0]"))
+	wt.False(tm.Get("fruit").IsValid())
+
+	wt.Equal(tm.Delete("servers"), NotFound)
+	wt.Equal(tm.Delete("products[2]"), NotFound)
+
+	ntm, err := Parse([]byte(tm.String()))
+	wt.Nil(err)
+	wt.Equal(ntm.Get("database.ports").IntArray(), []int64{8001, 8002})
+	wt.False(ntm.Get("servers.alpha.ip").IsValid())
+}
+
+func TestTomlMove(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	id := tm["servers.alpha.ip"].Id()
+	wt.Nil(tm.Move("servers.alpha", "servers.gamma"))
+	wt.False(tm.Get("servers.alpha").IsValid())
+	wt.False(tm.Get("servers.alpha.ip").IsValid())
+	wt.Equal(tm["servers.gamma"].Comments(), []string{"# You can indent as you please. Tabs or spaces. TOML don't care."})
+	wt.Equal(tm["servers.gamma.ip"].String(), "10.0.0.1")
+	wt.Equal(tm["servers.gamma.ip"].Id(), id)
+
+	wt.Nil(tm.Move("owner.dob", "owner.birthday"))
+	wt.Equal(tm["owner.birthday"].Comment(), "# First class dates? Why not?")
+
+	wt.Nil(tm.Move("title", "meta.title"))
+	wt.Equal(tm["meta"].Kind(), TableName)
+	wt.Equal(tm["meta.title"].String(), "TOML Example")
+
+	wt.Nil(tm.Move("products[1].color", "products[1].colour"))
+	wt.Equal(tm.Get("products[1].colour").String(), "gray")
+
+	wt.Nil(tm.Move("fruit", "fruits"))
+	wt.Equal(tm.Get("fruits[0].variety[1].name").String(), "granny smith")
+
+	wt.Equal(tm.Move("servers.beta", "servers.gamma"), Redeclared)
+	wt.Equal(tm.Move("servers", "servers.beta.x"), NotSupported)
+	wt.Equal(tm.Move("database.ports[0]", "database.port"), NotSupported)
+	wt.Equal(tm.Move("nothing", "something"), NotFound)
+
+	ntm, err := Parse([]byte(tm.String()))
+	wt.Nil(err)
+	wt.Equal(ntm.Get("servers.gamma.dc").String(), "eqdc10")
+	wt.Equal(ntm.Get("meta.title").String(), "TOML Example")
+}
+
+func TestTomlImplicit(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte("[a.b]\nc = 1\n"))
+	wt.Nil(err)
+
+	wt.Nil(tm.Move("a", "x"))
+	wt.Equal(tm.Keys(), []string{"x.b", "x.b.c"})
+	wt.Equal(tm.Get("x.b.c").Integer(), 1)
+	wt.Equal(tm.Move("a", "y"), NotFound)
+
+	wt.Nil(tm.Set("y", 2))
+	wt.Equal(tm.Move("y", "x"), Redeclared)
+	wt.Equal(tm.Move("x", "x.b.d"), NotSupported)
+	wt.Equal(tm.Keys(), []string{"x.b", "x.b.c", "y"})
+
+	wt.Nil(tm.Delete("x"))
+	wt.Equal(tm.Keys(), []string{"y"})
+	wt.Equal(tm.Delete("x"), NotFound)
+
+	_, ok := tm.Lookup("x")
+	wt.False(ok)
+}
+
+func TestValueOf(t *testing.T) {
+	wt := want.T(t)
+
+	v, err := ValueOf([]interface{}{})
+	wt.Nil(err)
+	wt.Equal(v.Kind(), Array)
+	wt.Equal(v.Len(), 0)
+
+	v, err = ValueOf([]float64{1, 2})
+	wt.Nil(err)
+	wt.Equal(v.Kind(), FloatArray)
+
+	nv, err := ValueOf(v)
+	wt.Nil(err)
+	wt.True(nv != v && nv.Equal(v))
+
+	_, err = ValueOf([]interface{}{1, "a"})
+	wt.Equal(err, NotSupported)
+	_, err = ValueOf(nil)
+	wt.Equal(err, NotSupported)
+}
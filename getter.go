@@ -0,0 +1,336 @@
+package toml
+
+import (
+	"time"
+)
+
+// KindError 是 Try 和 Must 方法在值的 Kind 不符时返回的错误.
+type KindError struct {
+	Path string
+	Want Kind
+	Got  Kind
+}
+
+func (e *KindError) Error() string {
+	return "toml: " + e.Path + ": want " + e.Want.String() + ", got " + e.Got.String()
+}
+
+/**
+try 返回 path 对应的 kind 值.
+路径格式错误时返回 InvalidPath, 不存在时返回 NotFound, Kind 不符时返回 *KindError.
+空数组可以作为任何数组.
+*/
+func (p Toml) try(path string, kind Kind) (*Value, error) {
+	if _, err := splitPath(path); err != nil {
+		return nil, err
+	}
+	it, ok := p.Lookup(path)
+	if !ok {
+		// ArrayOfTables 中的 Table
+		if _, ok = p.LookupTable(path); ok {
+			return nil, &KindError{path, kind, TableName}
+		}
+		return nil, NotFound
+	}
+	if it.kind != kind && !(isArrayKind(kind) && it.kind == Array && it.Len() == 0) {
+		return nil, &KindError{path, kind, it.kind}
+	}
+	return it.Value, nil
+}
+
+/**
+getter 实现 Toml 和 Lenient 的 Try, Get 和 Must 方法. coerce 是可选的, 在 Kind 不符时调用,
+返回由 v 转换的 kind 值, 不能转换时返回 nil. Toml 的 coerce 为 nil, 不做任何转换.
+*/
+type getter struct {
+	Toml
+	coerce func(path string, v *Value, kind Kind) *Value
+}
+
+// try 同 Toml.try, Kind 不符时使用 coerce 转换, ArrayOfTables 中的 Table 不能被转换.
+func (g getter) try(path string, kind Kind) (*Value, error) {
+	v, err := g.Toml.try(path, kind)
+	ke, ok := err.(*KindError)
+	if !ok || ke.Got >= TableName || g.coerce == nil {
+		return v, err
+	}
+	if v = g.coerce(path, g.Get(path).Value, kind); v == nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (g getter) tryString(path string) (string, error) {
+	v, err := g.try(path, String)
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+func (g getter) tryInt(path string) (int64, error) {
+	v, err := g.try(path, Integer)
+	if err != nil {
+		return 0, err
+	}
+	return v.Int(), nil
+}
+
+func (g getter) tryFloat(path string) (float64, error) {
+	v, err := g.try(path, Float)
+	if err != nil {
+		return 0, err
+	}
+	return v.Float(), nil
+}
+
+func (g getter) tryBool(path string) (bool, error) {
+	v, err := g.try(path, Boolean)
+	if err != nil {
+		return false, err
+	}
+	return v.Boolean(), nil
+}
+
+func (g getter) tryTime(path string) (time.Time, error) {
+	v, err := g.try(path, Datetime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return v.Datetime(), nil
+}
+
+func (g getter) tryStringSlice(path string) ([]string, error) {
+	v, err := g.try(path, StringArray)
+	if err != nil {
+		return nil, err
+	}
+	if v.kind == Array {
+		return []string{}, nil
+	}
+	return v.StringArray(), nil
+}
+
+func (g getter) tryIntSlice(path string) ([]int64, error) {
+	v, err := g.try(path, IntegerArray)
+	if err != nil {
+		return nil, err
+	}
+	if v.kind == Array {
+		return []int64{}, nil
+	}
+	return v.IntArray(), nil
+}
+
+// getString 返回 tryString 的值, 发生错误时返回 def. 其他 get 方法与此相同.
+func (g getter) getString(path, def string) string {
+	s, err := g.tryString(path)
+	if err != nil {
+		return def
+	}
+	return s
+}
+
+func (g getter) getInt(path string, def int64) int64 {
+	i, err := g.tryInt(path)
+	if err != nil {
+		return def
+	}
+	return i
+}
+
+func (g getter) getFloat(path string, def float64) float64 {
+	f, err := g.tryFloat(path)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+func (g getter) getBool(path string, def bool) bool {
+	b, err := g.tryBool(path)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+func (g getter) getTime(path string, def time.Time) time.Time {
+	t, err := g.tryTime(path)
+	if err != nil {
+		return def
+	}
+	return t
+}
+
+func (g getter) getStringSlice(path string, def []string) []string {
+	a, err := g.tryStringSlice(path)
+	if err != nil {
+		return def
+	}
+	return a
+}
+
+func (g getter) getIntSlice(path string, def []int64) []int64 {
+	a, err := g.tryIntSlice(path)
+	if err != nil {
+		return def
+	}
+	return a
+}
+
+// mustString 返回 tryString 的值, 发生错误时 panic. 其他 must 方法与此相同.
+func (g getter) mustString(path string) string {
+	s, err := g.tryString(path)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (g getter) mustInt(path string) int64 {
+	i, err := g.tryInt(path)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+func (g getter) mustFloat(path string) float64 {
+	f, err := g.tryFloat(path)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (g getter) mustBool(path string) bool {
+	b, err := g.tryBool(path)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (g getter) mustTime(path string) time.Time {
+	t, err := g.tryTime(path)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func (g getter) mustStringSlice(path string) []string {
+	a, err := g.tryStringSlice(path)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func (g getter) mustIntSlice(path string) []int64 {
+	a, err := g.tryIntSlice(path)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+/**
+TryString 返回 path 对应的 String, 访问路径的格式参见 Lookup, 包括 ArrayOfTables 中的值.
+不存在时返回 NotFound, Kind 不是 String 时返回 *KindError, 路径格式错误时返回 InvalidPath.
+其他 Try 方法与此相同. 需要类型转换时参见 Lenient.
+
+	s, err := tm.TryString("products[1].name")
+	if err == toml.NotFound {
+		...
+	}
+*/
+func (p Toml) TryString(path string) (string, error) {
+	return getter{Toml: p}.tryString(path)
+}
+
+func (p Toml) TryInt(path string) (int64, error) {
+	return getter{Toml: p}.tryInt(path)
+}
+
+func (p Toml) TryFloat(path string) (float64, error) {
+	return getter{Toml: p}.tryFloat(path)
+}
+
+func (p Toml) TryBool(path string) (bool, error) {
+	return getter{Toml: p}.tryBool(path)
+}
+
+// TryTime 返回 Datetime 的值.
+func (p Toml) TryTime(path string) (time.Time, error) {
+	return getter{Toml: p}.tryTime(path)
+}
+
+// TryStringSlice 返回 StringArray 的值, 空数组返回长度为 0 的 slice.
+func (p Toml) TryStringSlice(path string) ([]string, error) {
+	return getter{Toml: p}.tryStringSlice(path)
+}
+
+// TryIntSlice 返回 IntegerArray 的值, 空数组返回长度为 0 的 slice.
+func (p Toml) TryIntSlice(path string) ([]int64, error) {
+	return getter{Toml: p}.tryIntSlice(path)
+}
+
+// GetString 返回 path 对应的 String, 不存在或者 Kind 不符时返回 def. 其他 Get 方法与此相同.
+func (p Toml) GetString(path, def string) string {
+	return getter{Toml: p}.getString(path, def)
+}
+
+func (p Toml) GetInt(path string, def int64) int64 {
+	return getter{Toml: p}.getInt(path, def)
+}
+
+func (p Toml) GetFloat(path string, def float64) float64 {
+	return getter{Toml: p}.getFloat(path, def)
+}
+
+func (p Toml) GetBool(path string, def bool) bool {
+	return getter{Toml: p}.getBool(path, def)
+}
+
+func (p Toml) GetTime(path string, def time.Time) time.Time {
+	return getter{Toml: p}.getTime(path, def)
+}
+
+func (p Toml) GetStringSlice(path string, def []string) []string {
+	return getter{Toml: p}.getStringSlice(path, def)
+}
+
+func (p Toml) GetIntSlice(path string, def []int64) []int64 {
+	return getter{Toml: p}.getIntSlice(path, def)
+}
+
+// MustString 同 TryString, 但是发生错误时 panic, 适用于必须存在的配置. 其他 Must 方法与此相同.
+func (p Toml) MustString(path string) string {
+	return getter{Toml: p}.mustString(path)
+}
+
+func (p Toml) MustInt(path string) int64 {
+	return getter{Toml: p}.mustInt(path)
+}
+
+func (p Toml) MustFloat(path string) float64 {
+	return getter{Toml: p}.mustFloat(path)
+}
+
+func (p Toml) MustBool(path string) bool {
+	return getter{Toml: p}.mustBool(path)
+}
+
+func (p Toml) MustTime(path string) time.Time {
+	return getter{Toml: p}.mustTime(path)
+}
+
+func (p Toml) MustStringSlice(path string) []string {
+	return getter{Toml: p}.mustStringSlice(path)
+}
+
+func (p Toml) MustIntSlice(path string) []int64 {
+	return getter{Toml: p}.mustIntSlice(path)
+}
@@ -0,0 +1,78 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+	"time"
+)
+
+func TestTomlGetters(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+	wt.Nil(tm.Set("empty", []string{}))
+	wt.Nil(tm.Set("ratio", 0.5))
+
+	wt.Equal(tm.GetString("title", ""), "TOML Example")
+	wt.Equal(tm.GetString("products[1].name", ""), "Nail")
+	wt.Equal(tm.GetString("nothing", "def"), "def")
+	wt.Equal(tm.GetString("database.connection_max", "def"), "def")
+	wt.Equal(tm.GetInt("database.connection_max", 0), int64(5000))
+	wt.Equal(tm.GetInt("products[0].sku", 0), int64(738594937))
+	wt.Equal(tm.GetFloat("ratio", 0), 0.5)
+	wt.Equal(tm.GetFloat("database.connection_max", 1.5), 1.5)
+	wt.Equal(tm.GetBool("database.enabled", false), true)
+	wt.Equal(tm.GetTime("owner.dob", time.Time{}), time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC))
+	wt.Equal(tm.GetStringSlice("clients.data[0]", nil), []string{"gamma", "delta"})
+	wt.Equal(tm.GetStringSlice("empty", nil), []string{})
+	wt.Equal(tm.GetStringSlice("database.ports", nil), []string(nil))
+	wt.Equal(tm.GetIntSlice("database.ports", nil), []int64{8001, 8001, 8002})
+	wt.Equal(tm.GetIntSlice("empty", nil), []int64{})
+	wt.Equal(tm.GetIntSlice("clients.data[0]", nil), []int64(nil))
+
+	// 缺少的值和零值可以区分
+	_, err = tm.TryInt("database.nothing")
+	wt.Equal(err, NotFound)
+	_, err = tm.TryInt("products[5].sku")
+	wt.Equal(err, NotFound)
+	_, err = tm.TryInt("products[")
+	wt.Equal(err, InvalidPath)
+
+	_, err = tm.TryInt("title")
+	ke, ok := err.(*KindError)
+	wt.True(ok)
+	wt.Equal(*ke, KindError{"title", Integer, String})
+	wt.Equal(err.Error(), "toml: title: want Integer, got String")
+
+	_, err = tm.TryString("products[0]")
+	wt.Equal(err, &KindError{"products[0]", String, TableName})
+	_, err = tm.TryBool("servers")
+	wt.Equal(err, &KindError{"servers", Boolean, TableName})
+
+	// coerce 只在 Kind 不符时调用, 不能用于 ArrayOfTables 中的 Table
+	g := getter{Toml: tm, coerce: func(path string, v *Value, kind Kind) *Value {
+		return &Value{kind: kind, v: int64(len(v.String()))}
+	}}
+	i, err := g.tryInt("title")
+	wt.Nil(err)
+	wt.Equal(i, int64(len("TOML Example")))
+	i, err = g.tryInt("products[0].sku")
+	wt.Equal(i, int64(738594937))
+	_, err = g.tryInt("products[0]")
+	wt.Equal(err, &KindError{"products[0]", Integer, TableName})
+
+	var nilToml Toml
+	_, err = nilToml.TryString("title")
+	wt.Equal(err, NotFound)
+	wt.Equal(nilToml.GetString("title", "def"), "def")
+
+	wt.Equal(tm.MustString("servers.alpha.ip"), "10.0.0.1")
+	wt.Equal(tm.MustInt("clients.data[1][0]"), int64(1))
+	wt.Equal(tm.MustBool("database.enabled"), true)
+	wt.Equal(tm.MustIntSlice("database.ports"), []int64{8001, 8001, 8002})
+
+	defer func() {
+		wt.Equal(recover(), NotFound)
+	}()
+	tm.MustFloat("nothing")
+}
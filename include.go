@@ -0,0 +1,163 @@
+package toml
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+var IncludeCycle = errors.New("include cycle")
+
+// IncludeError 是 Loader 无法载入被包含的文件时返回的错误.
+type IncludeError struct {
+	Pos     Location // include 的位置, Pos.File 是包含者
+	Include string   // 被包含的文件, 相对路径已与包含者所在的目录连接, 合并包含者时是全部文件
+	Err     error    // IncludeCycle, KindConflict, 读取或者解析该文件的错误
+}
+
+func (e *IncludeError) Error() string {
+	return "toml: " + e.Pos.String() + ": include " + e.Include + ": " + e.Err.Error()
+}
+
+/**
+Loader 载入 TOML 文件并处理 include, 这是可选的, LoadFile 不处理 include.
+文件的顶层 Key include 是 String 或者 StringArray, 列出被包含的文件:
+
+	include = ["common.toml", "db.toml"]
+
+	[server]
+	port = 8080
+
+规则:
+	相对路径相对于包含者所在的目录, 被包含的文件也可以 include.
+	被包含的文件依次使用 Merge 合并, 后面的优先, 包含者优先于它包含的全部文件.
+	include 本身不会出现在结果中, 它的注释被移到结果中第一个输出的元素之前, 行尾注释成为单独的一行.
+	循环包含返回 *IncludeError, 其 Err 为 IncludeCycle.
+	每个值的 Position().File 是该值最终来自的文件.
+
+	tm, err := toml.Loader{}.LoadFile("app.toml")
+*/
+type Loader struct {
+	Key   string       // 替代 "include" 的顶层 Key, 空值表示 "include"
+	Merge MergeOptions // 合并时使用的 MergeOptions
+}
+
+// LoadFile 载入 path 以及它包含的文件, 参见 Loader.
+func (l Loader) LoadFile(path string) (Toml, error) {
+	return l.load(path, nil)
+}
+
+// load 载入 path, stack 是包含链上各文件的绝对路径.
+func (l Loader) load(path string, stack []string) (Toml, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.Key
+	if key == "" {
+		key = "include"
+	}
+	it, ok := tm[key]
+	if !ok || it.Value == nil || it.kind == InvalidKind {
+		return tm, nil
+	}
+
+	var files []string
+	switch it.kind {
+	case String:
+		files = []string{it.String()}
+	case StringArray:
+		files = it.StringArray()
+	case Array:
+		// 空数组, 解析得到的空数组 Len() 为 -1
+		if it.Len() <= 0 {
+			break
+		}
+		fallthrough
+	default:
+		return nil, &IncludeError{it.Position(), key, NotSupported}
+	}
+	delete(tm, key)
+
+	stack = append(stack[:len(stack):len(stack)], abs)
+	var base Toml
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+		inc, err := l.include(file, stack)
+		if err == nil && base != nil {
+			inc, err = Merge(base, inc, l.Merge)
+		}
+		if err != nil {
+			if ie, ok := err.(*IncludeError); ok {
+				return nil, ie
+			}
+			return nil, &IncludeError{it.Position(), file, err}
+		}
+		base = inc
+	}
+	if base != nil {
+		tm, err = Merge(base, tm, l.Merge)
+		if err != nil {
+			return nil, &IncludeError{it.Position(), strings.Join(files, ", "), err}
+		}
+	}
+	moveComments(tm, it.Value)
+	return tm, nil
+}
+
+// include 载入被包含的文件, file 已经在 stack 中时返回 IncludeCycle.
+func (l Loader) include(file string, stack []string) (Toml, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stack {
+		if s == abs {
+			return nil, IncludeCycle
+		}
+	}
+	return l.load(file, stack)
+}
+
+// moveComments 把 v 的注释移到 tm 中第一个输出的元素之前, 没有元素时作为文档末尾的注释.
+func moveComments(tm Toml, v *Value) {
+	cs := append(aString{}, v.multiComments...)
+	if v.eolComment != "" {
+		cs = append(cs, v.eolComment)
+	}
+	if len(cs) == 0 {
+		return
+	}
+
+	// 顶层的值最先输出, 其次是 TableName 和 ArrayOfTables
+	var first Item
+	for _, k := range tm.validKeys() {
+		if k.kind < TableName && strings.IndexByte(k.key, '.') == -1 {
+			first = tm[k.key]
+			break
+		}
+		if k.kind >= TableName && first.Value == nil {
+			first = tm[k.key]
+		}
+	}
+
+	dst := first.Value
+	switch {
+	case dst == nil:
+		tm.Id()
+		dst = tm[iD].Value
+	case dst.kind == ArrayOfTables:
+		// 输出的是第一个 Table 的注释
+		et := first.TomlArray()[0]
+		et.Id()
+		dst = et[iD].Value
+	}
+	dst.multiComments = append(cs, dst.multiComments...)
+}
@@ -0,0 +1,103 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoaderLoadFile(t *testing.T) {
+	wt := want.T(t)
+
+	tm, err := Loader{}.LoadFile("tests/include/app.toml")
+	wt.Nil(err)
+
+	wt.False(tm.Get("include").IsValid())
+	wt.Equal(tm.Get("title").String(), "app")
+	wt.Equal(tm.Get("owner").String(), "ops")
+	wt.Equal(tm.Get("server.host").String(), "0.0.0.0")
+	wt.Equal(tm.Get("server.port").Integer(), 9090)
+	wt.Equal(tm.Get("database.name").String(), "app")
+	wt.Equal(tm.Get("database.pool").Integer(), 10)
+
+	wt.Equal(tm.Get("title").Position().File, "tests/include/app.toml")
+	wt.Equal(tm.Get("owner").Position().File, "tests/include/common.toml")
+	wt.Equal(tm.Get("server.port").Position().File, "tests/include/app.toml")
+	wt.Equal(tm.Get("server.host").Position().File, "tests/include/common.toml")
+	wt.Equal(tm.Get("database.name").Position().File, "tests/include/conf/db.toml")
+	wt.Equal(tm.Get("database.pool").Position().File, "tests/include/base.toml")
+
+	// include 的注释被移到第一个输出的元素之前
+	wt.Equal(tm.Get("title").Comments(), []string{"# 应用配置"})
+
+	// 没有 include 的文件同 LoadFile
+	tm, err = Loader{}.LoadFile("tests/include/base.toml")
+	wt.Nil(err)
+	wt.Equal(tm.Get("database.name").String(), "base")
+
+	// 自定义 Key, include 作为普通的值
+	tm, err = Loader{Key: "import"}.LoadFile("tests/include/app.toml")
+	wt.Nil(err)
+	wt.Equal(tm.Get("include").StringArray(), []string{"common.toml", "conf/db.toml"})
+	wt.False(tm.Get("owner").IsValid())
+}
+
+func TestLoaderError(t *testing.T) {
+	wt := want.T(t)
+
+	_, err := Loader{}.LoadFile("tests/include/cycle_a.toml")
+	ie, ok := err.(*IncludeError)
+	wt.True(ok)
+	wt.Equal(ie.Err, IncludeCycle)
+	wt.Equal(ie.Include, "tests/include/cycle_a.toml")
+	wt.Equal(ie.Error(), "toml: tests/include/cycle_b.toml:1:11: include tests/include/cycle_a.toml: include cycle")
+
+	_, err = Loader{}.LoadFile("tests/include/missing.toml")
+	ie, ok = err.(*IncludeError)
+	wt.True(ok)
+	wt.Equal(ie.Include, "tests/include/nothing.toml")
+	wt.True(os.IsNotExist(ie.Err))
+
+	dir, err := ioutil.TempDir("", "include")
+	wt.Nil(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.toml")
+	wt.Nil(ioutil.WriteFile(file, []byte("include = 1"), 0644))
+	_, err = Loader{}.LoadFile(file)
+	ie, ok = err.(*IncludeError)
+	wt.True(ok)
+	wt.Equal(ie.Err, NotSupported)
+
+	// 严格合并时的 Kind 冲突
+	wt.Nil(ioutil.WriteFile(file, []byte("include = \"b.toml\"\nx = 1"), 0644))
+	wt.Nil(ioutil.WriteFile(filepath.Join(dir, "b.toml"), []byte("x = \"b\""), 0644))
+	_, err = Loader{Merge: MergeOptions{Strict: true}}.LoadFile(file)
+	ie, ok = err.(*IncludeError)
+	wt.True(ok)
+	wt.Equal(ie.Err, KindConflict)
+}
+
+func TestLoaderComments(t *testing.T) {
+	wt := want.T(t)
+	dir, err := ioutil.TempDir("", "include")
+	wt.Nil(err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "a.toml")
+	wt.Nil(ioutil.WriteFile(file, []byte("# head\ninclude = \"b.toml\" # shared\n"), 0644))
+	wt.Nil(ioutil.WriteFile(filepath.Join(dir, "b.toml"), []byte("[[p]]\nx = 1\n"), 0644))
+	tm, err := Loader{}.LoadFile(file)
+	wt.Nil(err)
+	wt.Equal(tm.String(), "\n# head\n# shared\n[[p]]\n\tx = 1\n")
+
+	// 没有任何元素时作为文档末尾的注释
+	wt.Nil(ioutil.WriteFile(file, []byte("# head\ninclude = []\n"), 0644))
+	tm, err = Loader{}.LoadFile(file)
+	wt.Nil(err)
+	_, ok := tm["include"]
+	wt.False(ok)
+	id := tm.Id()
+	wt.Equal(id.Comments(), []string{"# head"})
+}
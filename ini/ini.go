@@ -0,0 +1,337 @@
+/**
+Package ini 在 INI 文件和 Toml 之间转换.
+
+支持的 INI 格式:
+	; 注释以 ";" 或 "#" 开头, 绑定到随后的 section 或 key
+	name = value        ; 行尾注释
+	[section]
+	key = 1
+	key: value          ; 也可以用 ":" 分隔
+	[section.sub]       ; 含有 "." 的 section 对应嵌套的 Table
+
+section 对应 TableName, 值按以下次序推断类型:
+	"..." 或 '...'      String, 双引号中的转义按 Go 的规则
+	true, false         Boolean, 不区分大小写
+	整数                 Integer
+	浮点数               Float
+	RFC3339 日期时间      Datetime
+	其他                 String, 去掉首尾空白
+不支持数组, 多行值以及没有 "=" 的 key.
+*/
+package ini
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/achun/tom-toml"
+)
+
+// Parse 把 INI 文本转换为 Toml, 注释被转换为 "#" 开头的 TOML 注释.
+func Parse(source []byte) (toml.Toml, error) {
+	tm := toml.New()
+	section := ""
+	var comments []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		if line[0] == ';' || line[0] == '#' {
+			comments = append(comments, "#"+line[1:])
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("ini: line %d: unclosed section", n)
+			}
+			eol, err := eolComment(line[end+1:])
+			if err != nil {
+				return nil, fmt.Errorf("ini: line %d: %v", n, err)
+			}
+
+			section = strings.TrimSpace(line[1:end])
+			if !validName(section, true) {
+				return nil, fmt.Errorf("ini: line %d: invalid section %q", n, section)
+			}
+
+			it, ok := tm[section]
+			if ok && it.Kind() != toml.TableName {
+				return nil, fmt.Errorf("ini: line %d: %q redeclared", n, section)
+			}
+			if !ok {
+				it = toml.GenItem(toml.TableName)
+				tm[section] = it
+			}
+			it.SetComments(append(it.Comments(), comments...))
+			if eol != "" {
+				it.SetComment(eol)
+			}
+			comments = nil
+			continue
+		}
+
+		pos := strings.IndexAny(line, "=:")
+		if pos == -1 {
+			return nil, fmt.Errorf("ini: line %d: missing '='", n)
+		}
+
+		key := strings.TrimSpace(line[:pos])
+		if !validName(key, false) {
+			return nil, fmt.Errorf("ini: line %d: invalid key %q", n, key)
+		}
+
+		v, eol, err := value(strings.TrimSpace(line[pos+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("ini: line %d: %v", n, err)
+		}
+		v.SetComments(comments)
+		v.SetComment(eol)
+		comments = nil
+
+		if section != "" {
+			key = section + "." + key
+		}
+		if _, ok := tm[key]; ok {
+			return nil, fmt.Errorf("ini: line %d: %q redeclared", n, key)
+		}
+		tm[key] = toml.Item{Value: v}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// 文件末尾的注释
+	tm.SetComments(comments)
+	return tm, nil
+}
+
+// LoadFile 读取并转换 INI 文件.
+func LoadFile(path string) (toml.Toml, error) {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(source)
+}
+
+func validName(s string, section bool) bool {
+	if s == "" || strings.IndexAny(s, " \t=#;[]\"'") != -1 {
+		return false
+	}
+	if !section {
+		return strings.IndexByte(s, '.') == -1
+	}
+	for _, name := range strings.Split(s, ".") {
+		if name == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// eolComment 返回 s 中的行尾注释, s 中只能有空白和注释.
+func eolComment(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	if s[0] != ';' && s[0] != '#' {
+		return "", fmt.Errorf("unexpected %q", s)
+	}
+	return "#" + s[1:], nil
+}
+
+// value 推断 s 的类型, 返回值和行尾注释.
+func value(s string) (v *toml.Value, eol string, err error) {
+	v = toml.NewValue(toml.InvalidKind)
+
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		end := closing(s)
+		if end == -1 {
+			return nil, "", fmt.Errorf("unclosed string %s", s)
+		}
+		if eol, err = eolComment(s[end+1:]); err != nil {
+			return
+		}
+
+		str := s[1:end]
+		if s[0] == '"' {
+			if str, err = strconv.Unquote(s[:end+1]); err != nil {
+				return
+			}
+		}
+		return v, eol, v.Set(str)
+	}
+
+	// 行尾注释需要以空白开头, 以免误判 "a#b" 这样的值
+	for i := 1; i < len(s); i++ {
+		if (s[i] == ';' || s[i] == '#') && (s[i-1] == ' ' || s[i-1] == '\t') {
+			eol = "#" + s[i+1:]
+			s = strings.TrimSpace(s[:i])
+			break
+		}
+	}
+	return v, eol, v.Set(infer(s))
+}
+
+// closing 返回与 s[0] 匹配的引号的位置.
+func closing(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if s[0] == '"' {
+				i++
+			}
+		case s[0]:
+			return i
+		}
+	}
+	return -1
+}
+
+func infer(s string) interface{} {
+	switch strings.ToLower(s) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if isFloat(s) {
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UTC()
+	}
+	return s
+}
+
+// isFloat 只接受十进制的浮点数, 排除 "inf", "nan" 之类的单词.
+func isFloat(s string) bool {
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return false
+	}
+	return strings.Trim(s, "+-0123456789.eE") == ""
+}
+
+/**
+Marshal 把 Toml 转换为 INI 文本, 注释和 section 的次序被保留.
+Table 被输出为 section, 嵌套的 Table 使用 "." 连接的名称, 比如 [servers.alpha].
+数组和 ArrayOfTables 无法用 INI 表示, 会返回错误.
+字符串在需要时输出为带引号的形式, 以便 Parse 能够得到相同的类型.
+*/
+func Marshal(tm toml.Toml) ([]byte, error) {
+	var (
+		tops     []string
+		sections = map[string][]string{}
+		order    []string
+	)
+
+	for _, key := range tm.Keys() {
+		it := tm[key]
+		switch it.Kind() {
+		case toml.TableName:
+			if _, ok := sections[key]; !ok {
+				sections[key] = nil
+				order = append(order, key)
+			}
+			continue
+		case toml.ArrayOfTables:
+			return nil, fmt.Errorf("ini: %s: ArrayOfTables %v", key, toml.NotSupported)
+		case toml.String, toml.Integer, toml.Float, toml.Boolean, toml.Datetime:
+		default:
+			return nil, fmt.Errorf("ini: %s: %s %v", key, it.Kind(), toml.NotSupported)
+		}
+
+		pos := strings.LastIndex(key, ".")
+		if pos == -1 {
+			tops = append(tops, key)
+			continue
+		}
+
+		// 没有 TableName 的上级也输出为 section
+		section := key[:pos]
+		if _, ok := sections[section]; !ok {
+			order = append(order, section)
+		}
+		sections[section] = append(sections[section], key)
+	}
+
+	var buf bytes.Buffer
+	for _, key := range tops {
+		writeKey(&buf, tm, key, key)
+	}
+
+	for _, section := range order {
+		if buf.Len() != 0 {
+			buf.WriteString("\n")
+		}
+		if it, ok := tm[section]; ok {
+			writeComments(&buf, it.Comments())
+			buf.WriteString("[" + section + "]" + comment(it.Comment()) + "\n")
+		} else {
+			buf.WriteString("[" + section + "]\n")
+		}
+
+		for _, key := range sections[section] {
+			writeKey(&buf, tm, key, key[len(section)+1:])
+		}
+	}
+
+	id := tm.Id()
+	if len(id.Comments()) != 0 {
+		buf.WriteString("\n")
+		writeComments(&buf, id.Comments())
+	}
+	return buf.Bytes(), nil
+}
+
+func writeComments(buf *bytes.Buffer, comments []string) {
+	for _, s := range comments {
+		buf.WriteString(";" + strings.TrimPrefix(s, "#") + "\n")
+	}
+}
+
+func comment(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " ;" + strings.TrimPrefix(s, "#")
+}
+
+func writeKey(buf *bytes.Buffer, tm toml.Toml, key, name string) {
+	it := tm[key]
+	writeComments(buf, it.Comments())
+
+	s := it.String()
+	switch it.Kind() {
+	case toml.String:
+		if str, ok := infer(s).(string); !ok || str != s || s == "" ||
+			strings.IndexAny(s, "\"';#\\\n\r\t") != -1 || strings.TrimSpace(s) != s {
+			s = strconv.Quote(s)
+		}
+	case toml.Float:
+		if strings.IndexAny(s, ".eE") == -1 {
+			s += ".0"
+		}
+	case toml.Datetime:
+		s = it.Datetime().Format(time.RFC3339Nano)
+	}
+	buf.WriteString(name + " = " + s + comment(it.Comment()) + "\n")
+}
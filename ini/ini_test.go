@@ -0,0 +1,106 @@
+package ini
+
+import (
+	"github.com/achun/testing-want"
+	"github.com/achun/tom-toml"
+	"testing"
+	"time"
+)
+
+const source = `; application settings
+name = demo
+debug = true ; enable debug output
+
+# database connection
+[database]
+host = 192.168.1.1
+port = 5432
+timeout: 2.5
+password = "p#ss;word"
+created = 1979-05-27T07:32:00Z
+path = C:\data
+
+[servers.alpha]
+ip = 10.0.0.1
+
+; end of file
+`
+
+func TestParse(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte(source))
+	wt.Nil(err)
+
+	wt.Equal(tm["name"].String(), "demo")
+	wt.Equal(tm["name"].Comments(), []string{"# application settings"})
+	wt.True(tm["debug"].Boolean())
+	wt.Equal(tm["debug"].Comment(), "# enable debug output")
+
+	wt.Equal(tm["database"].Kind(), toml.TableName)
+	wt.Equal(tm["database"].Comments(), []string{"# database connection"})
+	wt.Equal(tm["database.host"].String(), "192.168.1.1")
+	wt.Equal(tm["database.port"].Integer(), 5432)
+	wt.Equal(tm["database.timeout"].Float(), 2.5)
+	wt.Equal(tm["database.password"].String(), "p#ss;word")
+	wt.Equal(tm["database.created"].Datetime(), time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC))
+	wt.Equal(tm["database.path"].String(), `C:\data`)
+	wt.Equal(tm.Get("servers.alpha.ip").String(), "10.0.0.1")
+	id := tm.Id()
+	wt.Equal(id.Comments(), []string{"# end of file"})
+
+	// 输出的 TOML 可以被再次解析
+	ntm, err := toml.Parse([]byte(tm.String()))
+	wt.Nil(err, tm.String())
+	wt.True(toml.Equal(tm, ntm, toml.IgnoreOrder), tm.String())
+
+	for _, s := range []string{"[a", "[a] b", "key", "a b = 1", "a = 1\na = 2", `a = "b`} {
+		_, err = Parse([]byte(s))
+		wt.NotNil(err, s)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte(source))
+	wt.Nil(err)
+
+	b, err := Marshal(tm)
+	wt.Nil(err)
+	wt.Equal(string(b), `; application settings
+name = demo
+debug = true ; enable debug output
+
+; database connection
+[database]
+host = 192.168.1.1
+port = 5432
+timeout = 2.5
+password = "p#ss;word"
+created = 1979-05-27T07:32:00Z
+path = "C:\\data"
+
+[servers.alpha]
+ip = 10.0.0.1
+
+; end of file
+`)
+
+	ntm, err := Parse(b)
+	wt.Nil(err)
+	wt.True(toml.Equal(tm, ntm, toml.IgnoreOrder))
+
+	tm, err = toml.LoadFile("../tests/example.toml")
+	wt.Nil(err)
+	_, err = Marshal(tm)
+	wt.NotNil(err)
+
+	tm = toml.New()
+	wt.Nil(tm.Set("version", "1"))
+	wt.Nil(tm.Set("ratio", 1.0))
+	b, err = Marshal(tm)
+	wt.Nil(err)
+	ntm, err = Parse(b)
+	wt.Nil(err)
+	wt.Equal(ntm["version"].Kind(), toml.String)
+	wt.Equal(ntm["ratio"].Kind(), toml.Float)
+}
@@ -0,0 +1,162 @@
+package toml
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+var CyclicReference = errors.New("cyclic reference")
+
+// InterpolateError 是 Interpolate 无法展开 ${...} 时返回的错误.
+type InterpolateError struct {
+	Path string   // 含有 ${...} 的值的访问路径, 比如 "database.dsn", "hosts[1]"
+	Pos  Location // 该值的位置
+	Name string   // ${...} 中的名称, 不含默认值
+	Err  error    // NotFound, CyclicReference, NotSupported 或 InValidFormat
+}
+
+func (e *InterpolateError) Error() string {
+	s := "toml: "
+	if pos := e.Pos.String(); pos != "" {
+		s += pos + ": "
+	}
+	return s + e.Path + ": ${" + e.Name + "}: " + e.Err.Error()
+}
+
+/**
+Interpolate 展开 String 以及数组中 String 元素里的 ${...}, 包括 ArrayOfTables 中的值.
+这是可选的步骤, Parse 和 LoadFile 不会自动调用.
+
+	dsn = "postgres://${DB_USER}@${DB_HOST:-localhost}/app"
+	log_dir = "${paths.root}/logs"
+	price = "$${amount}"       # 得到 "${amount}"
+
+规则:
+	${name}             name 是 p 中的访问路径时使用该值, 否则使用 lookup(name)
+	${name:-default}    同上, 都不存在时使用 default, default 不会被展开
+	$${                 表示字面值 "${"
+引用的值是 String 时先展开它, 其他非数组, 非 Table 的值使用 String() 的结果.
+lookup 为 nil 时使用 os.LookupEnv, 测试时可以传入模拟的环境变量.
+
+无法展开时返回 *InterpolateError, 此时 p 不会被修改.
+*/
+func (p Toml) Interpolate(lookup func(name string) (string, bool)) error {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	in := &interpolator{
+		tm:       p,
+		lookup:   lookup,
+		visiting: map[*Value]bool{},
+		done:     map[*Value]string{},
+	}
+
+	err := p.Walk(func(path []string, it Item, depth int) error {
+		if it.kind != String {
+			return nil
+		}
+		_, err := in.value(JoinPath(path), it.Value)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for v, s := range in.done {
+		v.v = s
+	}
+	return nil
+}
+
+// interpolator 保存展开的结果, 全部成功后才写回 Value.
+type interpolator struct {
+	tm       Toml
+	lookup   func(string) (string, bool)
+	visiting map[*Value]bool
+	done     map[*Value]string
+}
+
+// value 返回 String 值 v 展开后的结果, path 是 v 的访问路径.
+func (in *interpolator) value(path string, v *Value) (string, error) {
+	if s, ok := in.done[v]; ok {
+		return s, nil
+	}
+
+	in.visiting[v] = true
+	s, err := in.expand(path, v)
+	delete(in.visiting, v)
+	if err != nil {
+		return "", err
+	}
+
+	in.done[v] = s
+	return s, nil
+}
+
+func (in *interpolator) expand(path string, v *Value) (string, error) {
+	src := v.v.(string)
+	if !strings.Contains(src, "${") {
+		return src, nil
+	}
+
+	var buf []byte
+	for i := 0; i < len(src); {
+		if strings.HasPrefix(src[i:], "$${") {
+			buf = append(buf, "${"...)
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(src[i:], "${") {
+			buf = append(buf, src[i])
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(src[i:], '}')
+		if end == -1 {
+			return "", &InterpolateError{path, v.Position(), src[i+2:], InValidFormat}
+		}
+		name, def, hasDef := src[i+2:i+end], "", false
+		if pos := strings.Index(name, ":-"); pos != -1 {
+			name, def, hasDef = name[:pos], name[pos+2:], true
+		}
+		i += end + 1
+
+		s, err := in.resolve(name)
+		if err == NotFound && hasDef {
+			s, err = def, nil
+		}
+		if err != nil {
+			if ie, ok := err.(*InterpolateError); ok {
+				return "", ie
+			}
+			return "", &InterpolateError{path, v.Position(), name, err}
+		}
+		buf = append(buf, s...)
+	}
+	return string(buf), nil
+}
+
+// resolve 返回 ${name} 的值, 先查找 in.tm 中的访问路径, 然后是 lookup.
+func (in *interpolator) resolve(name string) (string, error) {
+	it, ok := in.tm.Lookup(name)
+	if !ok {
+		if s, ok := in.lookup(name); ok {
+			return s, nil
+		}
+		return "", NotFound
+	}
+
+	switch {
+	case it.kind == String:
+		if in.visiting[it.Value] {
+			return "", CyclicReference
+		}
+		return in.value(name, it.Value)
+	case it.kind > String && it.kind <= Datetime:
+		return it.String(), nil
+	}
+	return "", NotSupported
+}
@@ -0,0 +1,88 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+)
+
+func fakeEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		s, ok := env[name]
+		return s, ok
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	wt := want.T(t)
+
+	tm, err := Parse([]byte(`
+dsn = "postgres://${DB_USER}@${DB_HOST:-localhost}/app"
+log_dir = "${paths.root}/logs"
+price = "$${amount} ${DB_USER}$"
+hosts = ["${DB_HOST:-a}", "${paths.root}"]
+nested = [["${port}"], [1]]
+empty = "${NOTHING:-}"
+
+[paths]
+root = "${HOME}/srv"
+port = 8080
+
+[[servers]]
+url = "http://${DB_USER}:${paths.port}${servers[0].path}"
+path = "/api"
+`))
+	wt.Nil(err)
+
+	wt.Nil(tm.Interpolate(fakeEnv(map[string]string{
+		"DB_USER": "admin",
+		"HOME":    "/home/x",
+		"port":    "22",
+	})))
+
+	wt.Equal(tm.Get("dsn").String(), "postgres://admin@localhost/app")
+	wt.Equal(tm.Get("log_dir").String(), "/home/x/srv/logs")
+	wt.Equal(tm.Get("paths.root").String(), "/home/x/srv")
+	wt.Equal(tm.Get("price").String(), "${amount} admin$")
+	wt.Equal(tm.Get("hosts").StringArray(), []string{"a", "/home/x/srv"})
+	wt.Equal(tm.Get("nested[0][0]").String(), "22")
+	wt.Equal(tm.Get("empty").String(), "")
+	wt.Equal(tm.Get("servers[0].url").String(), "http://admin:8080/api")
+}
+
+func TestInterpolateError(t *testing.T) {
+	wt := want.T(t)
+	env := fakeEnv(map[string]string{"A": "a"})
+
+	for _, c := range []struct {
+		source string
+		path   string
+		name   string
+		err    error
+	}{
+		{`x = "${B}"`, "x", "B", NotFound},
+		{`x = "${A"`, "x", "A", InValidFormat},
+		{`x = "${t}"` + "\n[t]\ny = 1", "x", "t", NotSupported},
+		{`x = "${a}"` + "\na = \"${b}\"\nb = \"${a}\"", "b", "a", CyclicReference},
+		{`x = "${x}"`, "x", "x", CyclicReference},
+	} {
+		tm, err := Parse([]byte(c.source))
+		wt.Nil(err)
+
+		err = tm.Interpolate(env)
+		ie, ok := err.(*InterpolateError)
+		wt.True(ok, c.source)
+		if !ok {
+			continue
+		}
+		wt.Equal(ie.Path, c.path, c.source)
+		wt.Equal(ie.Name, c.name, c.source)
+		wt.Equal(ie.Err, c.err, c.source)
+	}
+
+	// 出错时不修改
+	tm, err := Parse([]byte("a = \"${A}\"\nb = \"${B}\""))
+	wt.Nil(err)
+	err = tm.Interpolate(env)
+	wt.Equal(err.Error(), "toml: 2:5: b: ${B}: not found")
+	wt.Equal(tm.Get("a").String(), "${A}")
+}
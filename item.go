@@ -113,8 +113,9 @@ type Value struct {
 	kind          Kind
 	idx           int
 	v             interface{}
-	eolComment    string  // end of line comment
-	multiComments aString // Multi-line comments
+	eolComment    string    // end of line comment
+	multiComments aString   // Multi-line comments
+	loc           *Location // 解析得到的位置, 不会被修改
 	//key           string  // cached key name for TOML formatter
 }
 
@@ -348,7 +349,12 @@ func conv(s string, kind Kind) (v interface{}, err error) {
 	return
 }
 
-// SetAs是个便捷方法, 通过参数 kind 对 string 参数进行转换并执行 Set.
+/**
+SetAs是个便捷方法, 通过参数 kind 对 string 参数进行转换并执行 Set.
+kind 为 typeArray 时, s 可以是 TOML 格式的数组, 比如 `[8001, 8002]`,
+也可以是逗号分隔的元素, 比如 `8001, 8002`, 元素去掉首尾空白后按元素的 Kind 转换.
+kind 为 Array 时 s 必须是 TOML 格式的数组, 转换后的 Kind 由数组本身决定.
+*/
 func (p *Value) SetAs(s string, kind Kind) (err error) {
 	if p.canNotSet(kind) {
 		return NotSupported
@@ -380,6 +386,13 @@ func (p *Value) SetAs(s string, kind Kind) (err error) {
 		if err == nil {
 			p.v = v.UTC()
 		}
+	case StringArray, IntegerArray, FloatArray, BooleanArray, DatetimeArray, Array:
+		var v *Value
+		v, err = arrayAs(s, kind)
+		if err == nil {
+			p.v = v.v
+			kind = v.kind
+		}
 	default:
 		return NotSupported
 	}
@@ -390,6 +403,54 @@ func (p *Value) SetAs(s string, kind Kind) (err error) {
 	return
 }
 
+// arrayAs 把 s 转换为 kind 数组, 参见 SetAs.
+func arrayAs(s string, kind Kind) (*Value, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		tm, err := Parse([]byte("v = " + s))
+		if err != nil {
+			return nil, err
+		}
+		v := tm["v"].Value
+		if v.kind == Array && v.Len() == 0 {
+			v.kind = kind
+		}
+		if kind != Array && v.kind != kind {
+			return nil, NotSupported
+		}
+		v.clearLocation()
+		return v, nil
+	}
+
+	if kind == Array {
+		return nil, NotSupported
+	}
+
+	v := &Value{kind: kind, v: []*Value{}}
+	if s == "" {
+		return v, nil
+	}
+	for _, es := range strings.Split(s, ",") {
+		e := &Value{}
+		if err := e.SetAs(strings.TrimSpace(es), kind+String-StringArray); err != nil {
+			return nil, err
+		}
+		if err := v.Add(e); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// clearLocation 清除 p 及其数组元素的位置, 用于不是由文档解析得到的值.
+func (p *Value) clearLocation() {
+	p.loc = nil
+	a, _ := p.v.([]*Value)
+	for _, e := range a {
+		e.clearLocation()
+	}
+}
+
 func asValue(i interface{}) (v *Value, ok bool) {
 	it, ok := i.(Item)
 	if ok {
@@ -930,17 +991,18 @@ func (it *Value) Apply(dst interface{}) (count int) {
 		return
 	}
 
-	return it.apply(vv)
+	return it.apply(vv, nil, "")
 }
 
-func (it *Value) apply(vv reflect.Value) (count int) {
+// apply 的 c 不为 nil 时按照 c.Policy 转换类型, path 是 it 的访问路径, 用于 Coercion.
+func (it *Value) apply(vv reflect.Value, c *Lenient, path string) (count int) {
 
 	vt := vv.Type()
 
 	switch vt.Kind() {
 	case reflect.Bool:
-		if it.kind == Boolean {
-			vv.SetBool(it.Boolean())
+		if v := c.coerce(path, it, Boolean); v != nil {
+			vv.SetBool(v.Boolean())
 			count++
 		}
 	case reflect.String:
@@ -949,18 +1011,27 @@ func (it *Value) apply(vv reflect.Value) (count int) {
 			count++
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if it.kind == Integer {
-			vv.SetInt(it.Int())
+		if vt == durationType && it.kind == String && c != nil && c.Policy&CoerceString != 0 {
+			d, err := time.ParseDuration(strings.TrimSpace(it.String()))
+			if err == nil {
+				c.warn(path, it, "Duration")
+				vv.SetInt(int64(d))
+				count++
+			}
+			break
+		}
+		if v := c.coerce(path, it, Integer); v != nil {
+			vv.SetInt(v.Int())
 			count++
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if it.kind == Integer {
-			vv.SetUint(it.UInt())
+		if v := c.coerce(path, it, Integer); v != nil {
+			vv.SetUint(v.UInt())
 			count++
 		}
 	case reflect.Float32, reflect.Float64:
-		if it.kind == Float {
-			vv.SetFloat(it.Float())
+		if v := c.coerce(path, it, Float); v != nil {
+			vv.SetFloat(v.Float())
 			count++
 		}
 	case reflect.Interface:
@@ -969,11 +1040,19 @@ func (it *Value) apply(vv reflect.Value) (count int) {
 			count++
 		}
 	case reflect.Struct:
-		if it.IsValid() && it.kind == Datetime && vt.String() == "time.Time" {
-			vv.Set(reflect.ValueOf(it.Datetime()))
+		if vt.String() != "time.Time" {
+			break
+		}
+		if v := c.coerce(path, it, Datetime); v != nil {
+			vv.Set(reflect.ValueOf(v.Datetime()))
 			count++
 		}
 	case reflect.Array, reflect.Slice:
+		// 单个值作为只有一个元素的数组, 元素赋值成功后才警告
+		single := it
+		if it.kind < StringArray && c != nil && c.Policy&CoerceSlice != 0 {
+			it = &Value{kind: it.kind + StringArray - String, v: []*Value{it}}
+		}
 
 		l := it.Len()
 		if l <= 0 {
@@ -991,7 +1070,10 @@ func (it *Value) apply(vv reflect.Value) (count int) {
 		}
 
 		for i := 0; i < l && i < vv.Len(); i++ {
-			count += it.Index(i).apply(vv.Index(i))
+			count += it.Index(i).apply(vv.Index(i), c, path+"["+strconv.Itoa(i)+"]")
+		}
+		if it != single && count != 0 {
+			c.warn(path, single, "Array")
 		}
 	}
 	return
@@ -67,3 +67,34 @@ func TestItemPlain(t *testing.T) {
 	wt.Equal(a.String(), "2012-01-02T13:11:14Z")
 	wt.Equal(a.String(), "2012-01-02T13:11:14Z")
 }
+
+func TestItemSetAsArray(t *testing.T) {
+	wt := want.T(t)
+	a := GenItem(0)
+
+	wt.Nil(a.SetAs("8001, 8002", IntegerArray))
+	wt.Equal(a.Kind(), IntegerArray)
+	wt.Equal(a.IntArray(), []int64{8001, 8002})
+
+	wt.Nil(a.SetAs("[8003]", IntegerArray))
+	wt.Equal(a.IntArray(), []int64{8003})
+
+	wt.Nil(a.SetAs("", IntegerArray))
+	wt.Equal(a.Kind(), IntegerArray)
+	wt.Equal(a.Len(), 0)
+
+	wt.NotNil(a.SetAs("1, x", IntegerArray))
+	wt.Equal(a.SetAs(`["a"]`, IntegerArray), NotSupported)
+	wt.Equal(a.SetAs("a", String), NotSupported)
+
+	b := GenItem(0)
+	wt.Nil(b.SetAs(`a , "b"`, StringArray))
+	wt.Equal(b.StringArray(), []string{"a", `"b"`})
+
+	c := GenItem(0)
+	wt.Nil(c.SetAs(`[[1, 2], ["a"]]`, Array))
+	wt.Equal(c.Kind(), Array)
+	wt.Equal(c.String(), `[[1, 2], ["a"]]`)
+	wt.Equal(c.Index(0).Position(), Location{})
+	wt.Equal(c.SetAs("1, 2", Array), NotSupported)
+}
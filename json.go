@@ -0,0 +1,234 @@
+package toml
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+MarshalJSON 实现 json.Marshaler, 输出 ToMap 的结果, 不含注释.
+Datetime 被输出为 RFC3339 格式的字符串.
+*/
+func (p Toml) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.ToMap())
+}
+
+/**
+UnmarshalJSON 实现 json.Unmarshaler, 用 JSON 对象替换 *p 的内容, 规则与 FromMap 相同.
+JSON 的整数转换为 Integer, 含有小数点或指数的数值转换为 Float,
+普通 JSON 无法区分字符串和 Datetime, 需要 Datetime 时请使用 tagged JSON.
+*/
+func (p *Toml) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+
+	tm, err := FromMap(jsonValue(m, InvalidKind).(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	*p = tm
+	return nil
+}
+
+/**
+TaggedJSON 以 toml-test (https://github.com/BurntSushi/toml-test) 使用的
+tagged JSON 格式输出 p, 每个值都带有类型, 比如:
+	{"type": "integer", "value": "1"}
+类型名为 string, integer, float, bool, datetime 和 array,
+数组采用与 TOML v0.2 同期的格式 {"type": "array", "value": [...]},
+TableName 输出为 JSON 对象, ArrayOfTables 输出为对象数组.
+*/
+func (p Toml) TaggedJSON() ([]byte, error) {
+	return json.Marshal(p.toMap((*Value).tagged))
+}
+
+func (p *Value) tagged() interface{} {
+	// 解析得到的空数组没有元素
+	if p != nil && p.kind == Array && p.v == nil {
+		return map[string]interface{}{"type": "array", "value": []interface{}{}}
+	}
+	if !p.IsValid() {
+		return nil
+	}
+
+	var s string
+	switch v := p.v.(type) {
+	case TomlArray:
+		a := make([]map[string]interface{}, len(v))
+		for i, tm := range v {
+			a[i] = tm.toMap((*Value).tagged)
+		}
+		return a
+	case []*Value:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = e.tagged()
+		}
+		return map[string]interface{}{"type": "array", "value": a}
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		s = strconv.FormatBool(v)
+	case time.Time:
+		s = v.Format(time.RFC3339Nano)
+	}
+	return map[string]string{"type": taggedTypes[p.kind], "value": s}
+}
+
+var taggedTypes = map[Kind]string{
+	String:   "string",
+	Integer:  "integer",
+	Float:    "float",
+	Boolean:  "bool",
+	Datetime: "datetime",
+}
+
+/**
+FromTaggedJSON 由 toml-test 的 tagged JSON 建立 Toml, 是 TaggedJSON 的逆操作.
+数组既可以是 {"type": "array", "value": [...]}, 也可以是新版 toml-test 使用的 JSON 数组,
+类型名 "boolean" 等同于 "bool". 数组必须满足 Value.Add 的要求.
+*/
+func FromTaggedJSON(data []byte) (Toml, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	tm := New()
+	if err := tm.fromTagged("", m); err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+func (p Toml) fromTagged(prefix string, m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if k == "" || strings.Contains(k, ".") {
+			return InvalidPath
+		}
+		key := prefix + k
+
+		x := m[k]
+		if v, ok, err := taggedValue(x); ok {
+			if err != nil {
+				return err
+			}
+			p[key] = Item{v}
+			continue
+		}
+
+		switch v := x.(type) {
+		case map[string]interface{}:
+			p[key] = GenItem(TableName)
+			if err := p.fromTagged(key+".", v); err != nil {
+				return err
+			}
+		case []interface{}:
+			ms, ok := asTables(v)
+			if !ok {
+				return InValidFormat
+			}
+			it := GenItem(ArrayOfTables)
+			for _, m := range ms {
+				elem := New()
+				if err := elem.fromTagged("", m); err != nil {
+					return err
+				}
+				it.AddTable(elem)
+			}
+			p[key] = it
+		default:
+			return InValidFormat
+		}
+	}
+	return nil
+}
+
+/**
+taggedValue 转换 tagged JSON 中的值, ok 表示 x 是值而不是 Table 或 ArrayOfTables.
+空的 JSON 数组被当作空的 Array.
+*/
+func taggedValue(x interface{}) (v *Value, ok bool, err error) {
+	switch t := x.(type) {
+	case []interface{}:
+		if _, ok = asTables(t); ok && !isTagged(t[0]) {
+			return nil, false, nil
+		}
+		v, err = taggedArray(t)
+		return v, true, err
+
+	case map[string]interface{}:
+		typ, ok := t["type"].(string)
+		if !ok || len(t) != 2 {
+			return nil, false, nil
+		}
+
+		if typ == "array" {
+			a, ok := t["value"].([]interface{})
+			if !ok {
+				return nil, true, InValidFormat
+			}
+			v, err = taggedArray(a)
+			return v, true, err
+		}
+
+		s, ok := t["value"].(string)
+		if !ok {
+			return nil, false, nil
+		}
+
+		kind := InvalidKind
+		for k, name := range taggedTypes {
+			if name == typ || typ == "boolean" && k == Boolean {
+				kind = k
+			}
+		}
+		if kind == InvalidKind {
+			return nil, true, NotSupported
+		}
+
+		v = NewValue(kind)
+		return v, true, v.SetAs(s, kind)
+	}
+	return nil, false, nil
+}
+
+// isTagged 判断 x 是否为带类型的值, 用于区分新版格式的数组和 ArrayOfTables.
+func isTagged(x interface{}) bool {
+	_, ok, _ := taggedValue(x)
+	return ok
+}
+
+func taggedArray(a []interface{}) (*Value, error) {
+	vs := make([]interface{}, len(a))
+	for i, x := range a {
+		v, ok, err := taggedValue(x)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, InValidFormat
+		}
+		vs[i] = v
+	}
+	return toValue(vs)
+}
@@ -0,0 +1,170 @@
+package toml
+
+import (
+	"encoding/json"
+	"github.com/achun/testing-want"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTomlMarshalJSON(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	b, err := json.Marshal(tm)
+	wt.Nil(err)
+
+	var m map[string]interface{}
+	wt.Nil(json.Unmarshal(b, &m))
+	wt.Equal(m["title"], "TOML Example")
+	wt.Equal(m["owner"].(map[string]interface{})["dob"], "1979-05-27T07:32:00Z")
+	wt.Equal(m["products"].([]interface{})[1].(map[string]interface{})["sku"], float64(284758393))
+
+	var ntm Toml
+	wt.Nil(json.Unmarshal(b, &ntm))
+	wt.Equal(ntm["database.ports"].IntArray(), []int64{8001, 8001, 8002})
+	wt.Equal(ntm["owner.dob"].Kind(), String)
+	wt.Equal(ntm.Get("fruit[0].variety[1].name").String(), "granny smith")
+
+	wt.Nil(json.Unmarshal([]byte(`{"pi": 3.14, "n": 1e3, "on": true}`), &ntm))
+	wt.Equal(len(ntm.validKeys()), 3)
+	wt.Equal(ntm["pi"].Kind(), Float)
+	wt.Equal(ntm["n"].Kind(), Float)
+	wt.NotNil(json.Unmarshal([]byte(`{"a": [1, "b"]}`), &ntm))
+}
+
+func TestTaggedJSON(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	b, err := tm.TaggedJSON()
+	wt.Nil(err)
+
+	ntm, err := FromTaggedJSON(b)
+	wt.Nil(err)
+	wt.True(Equal(tm, ntm, IgnoreComments, IgnoreOrder), ntm.String())
+
+	// 新版 toml-test 的数组格式
+	ntm, err = FromTaggedJSON([]byte(`{
+		"a": [{"type": "integer", "value": "1"}, {"type": "integer", "value": "2"}],
+		"b": {"type": "boolean", "value": "true"},
+		"c": [],
+		"d": {"type": {"type": "string", "value": "t"}, "value": {"type": "float", "value": "1.5"}}
+	}`))
+	wt.Nil(err)
+	wt.Equal(ntm["a"].IntArray(), []int64{1, 2})
+	wt.True(ntm["b"].Boolean())
+	wt.Equal(ntm["c"].Kind(), Array)
+	wt.Equal(ntm["d"].Kind(), TableName)
+	wt.Equal(ntm["d.value"].Float(), 1.5)
+
+	_, err = FromTaggedJSON([]byte(`{"a": {"type": "datetime-local", "value": "1979-05-27T07:32:00"}}`))
+	wt.Equal(err, NotSupported)
+	_, err = FromTaggedJSON([]byte(`{"a": {"type": "array", "value": [
+		{"type": "integer", "value": "1"}, {"type": "string", "value": "2"}]}}`))
+	wt.Equal(err, NotSupported)
+}
+
+// 解析器尚未通过的 toml-test 用例
+var tomlTestKnown = map[string]string{
+	"comments-everywhere": "multi-line array with comments followed by an end-of-line comment",
+	"duplicate-keys":      "duplicate keys are not detected",
+	"string-bad-escape":   "escapes are decoded by strconv.Unquote",
+	"text-after-integer":  "no newline required between key/value pairs",
+}
+
+// 离线的 toml-test 用例, 参见 tests/toml-test
+func TestTomlTestSuite(t *testing.T) {
+	wt := want.T(t)
+	known := func(file string) bool {
+		name := strings.TrimSuffix(filepath.Base(file), ".toml")
+		if reason, ok := tomlTestKnown[name]; ok {
+			t.Log("skip", file, reason)
+			return true
+		}
+		return false
+	}
+
+	files, err := filepath.Glob("tests/toml-test/valid/*.toml")
+	wt.Nil(err)
+	wt.True(len(files) != 0)
+
+	for _, file := range files {
+		if known(file) {
+			continue
+		}
+		source, err := ioutil.ReadFile(file)
+		wt.Nil(err)
+		expected, err := ioutil.ReadFile(strings.TrimSuffix(file, ".toml") + ".json")
+		wt.Nil(err)
+
+		tm, err := Parse(source)
+		if !wt.Nil(err, file) {
+			continue
+		}
+		got, err := tm.TaggedJSON()
+		wt.Nil(err)
+		wt.True(taggedEqual(got, expected), file, "\n", string(got))
+
+		// 反向: tagged JSON -> Toml -> TOML 文本 -> Toml
+		ntm, err := FromTaggedJSON(expected)
+		if !wt.Nil(err, file) {
+			continue
+		}
+		ntm, err = Parse([]byte(ntm.String()))
+		wt.Nil(err, file)
+		wt.Equal(ntm.ToMap(), tm.ToMap(), file)
+	}
+
+	files, err = filepath.Glob("tests/toml-test/invalid/*.toml")
+	wt.Nil(err)
+	for _, file := range files {
+		if known(file) {
+			continue
+		}
+		source, err := ioutil.ReadFile(file)
+		wt.Nil(err)
+		_, err = Parse(source)
+		wt.NotNil(err, file)
+	}
+}
+
+func taggedEqual(a, b []byte) bool {
+	var x, y interface{}
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(taggedNormal(x), taggedNormal(y))
+}
+
+// taggedNormal 统一 float 和 datetime 的文本表示.
+func taggedNormal(x interface{}) interface{} {
+	switch v := x.(type) {
+	case []interface{}:
+		for i, e := range v {
+			v[i] = taggedNormal(e)
+		}
+	case map[string]interface{}:
+		s, _ := v["value"].(string)
+		switch v["type"] {
+		case "float":
+			f, _ := strconv.ParseFloat(s, 64)
+			v["value"] = f
+		case "datetime":
+			d, _ := time.Parse(time.RFC3339Nano, s)
+			v["value"] = d.UTC().String()
+		default:
+			for k, e := range v {
+				v[k] = taggedNormal(e)
+			}
+		}
+	}
+	return x
+}
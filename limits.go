@@ -0,0 +1,114 @@
+package toml
+
+import (
+	"strconv"
+	"strings"
+)
+
+/**
+Limits 限制解析时使用的资源, 用于解析不可信的 TOML. 零值表示不限制.
+*/
+type Limits struct {
+	MaxBytes     int // 文档的字节数
+	MaxDepth     int // 数组的嵌套层数, [1, 2] 为 1 层, [[1], [2]] 为 2 层
+	MaxKeys      int // Key, [TableName] 和 [[ArrayOfTables]] 的总数
+	MaxStringLen int // 字符串在引号之间的字节数
+	MaxArrayLen  int // 数组的元素个数, 以及 ArrayOfTables 的 Table 个数
+}
+
+// LimitExceeded 是超出 Limits 时返回的错误, Pos 是超出限制的 Token 的位置.
+type LimitExceeded struct {
+	Limit string // Limits 的字段名, 比如 "MaxKeys"
+	Max   int
+	Pos   Position
+}
+
+func (e *LimitExceeded) Error() string {
+	return "toml: " + e.Pos.String() + ": " + e.Limit + " " + strconv.Itoa(e.Max) + " exceeded"
+}
+
+/**
+ParseLimits 同 Parse, 超出 limits 时停止解析并返回 *LimitExceeded.
+
+	tm, err := toml.ParseLimits(source, toml.Limits{MaxBytes: 1 << 20, MaxDepth: 8})
+	if le, ok := err.(*toml.LimitExceeded); ok {
+		fmt.Println(le.Limit, le.Pos)
+	}
+*/
+func ParseLimits(source []byte, limits Limits) (Toml, error) {
+	if limits.MaxBytes > 0 && len(source) > limits.MaxBytes {
+		pos, _ := Position{Line: 1, Column: 1}.advance(string(source[:limits.MaxBytes]), 0)
+		return nil, &LimitExceeded{"MaxBytes", limits.MaxBytes, pos}
+	}
+	return parseSource(source, "", &limiter{limits: limits, aot: map[string]int{}})
+}
+
+// limiter 在 Token 交给 tomlBuilder 之前检查 Limits.
+type limiter struct {
+	limits Limits
+	keys   int
+	arrays []int          // 每一层数组已有的元素个数
+	aot    map[string]int // 每个 ArrayOfTables 已有的 Table 个数
+}
+
+func (l *limiter) exceeded(limit string, max int, span Span) error {
+	return &LimitExceeded{limit, max, span.Start}
+}
+
+// element 为当前数组增加一个元素.
+func (l *limiter) element(span Span) error {
+	n := len(l.arrays)
+	if n == 0 {
+		return nil
+	}
+	l.arrays[n-1]++
+	if max := l.limits.MaxArrayLen; max > 0 && l.arrays[n-1] > max {
+		return l.exceeded("MaxArrayLen", max, span)
+	}
+	return nil
+}
+
+func (l *limiter) check(token Token, str string, span Span) error {
+	switch token {
+	case TokenKey, TokenTableName:
+		l.keys++
+	case TokenArrayOfTables:
+		l.keys++
+		name := str[2 : len(str)-2]
+		l.aot[name]++
+		if max := l.limits.MaxArrayLen; max > 0 && l.aot[name] > max {
+			return l.exceeded("MaxArrayLen", max, span)
+		}
+		// 新的 Table 中, 下级 ArrayOfTables 重新计数
+		prefix := name + "."
+		for k := range l.aot {
+			if strings.HasPrefix(k, prefix) {
+				delete(l.aot, k)
+			}
+		}
+	case TokenString:
+		if max := l.limits.MaxStringLen; max > 0 && len(str)-2 > max {
+			return l.exceeded("MaxStringLen", max, span)
+		}
+		return l.element(span)
+	case TokenInteger, TokenFloat, TokenBoolean, TokenDatetime:
+		return l.element(span)
+	case TokenArrayLeftBrack:
+		if err := l.element(span); err != nil {
+			return err
+		}
+		l.arrays = append(l.arrays, 0)
+		if max := l.limits.MaxDepth; max > 0 && len(l.arrays) > max {
+			return l.exceeded("MaxDepth", max, span)
+		}
+	case TokenArrayRightBrack:
+		if len(l.arrays) != 0 {
+			l.arrays = l.arrays[:len(l.arrays)-1]
+		}
+	}
+
+	if max := l.limits.MaxKeys; max > 0 && l.keys > max {
+		return l.exceeded("MaxKeys", max, span)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"io/ioutil"
+	"testing"
+)
+
+func TestParseLimits(t *testing.T) {
+	wt := want.T(t)
+	source, err := ioutil.ReadFile("tests/example.toml")
+	wt.Nil(err)
+
+	tm, err := ParseLimits(source, Limits{})
+	wt.Nil(err)
+	ptm, err := Parse(source)
+	wt.Nil(err)
+	wt.True(Equal(tm, ptm))
+
+	_, err = ParseLimits(source, Limits{
+		MaxBytes:     len(source),
+		MaxDepth:     2,
+		MaxKeys:      42,
+		MaxStringLen: 50,
+		MaxArrayLen:  3,
+	})
+	wt.Nil(err)
+
+	for _, c := range []struct {
+		limits Limits
+		err    LimitExceeded
+	}{
+		{Limits{MaxBytes: 40}, LimitExceeded{"MaxBytes", 40, Position{40, 3, 7}}},
+		{Limits{MaxDepth: 1}, LimitExceeded{"MaxDepth", 1, Position{612, 30, 10}}},
+		{Limits{MaxKeys: 36}, LimitExceeded{"MaxKeys", 36, Position{1041, 60, 3}}},
+		{Limits{MaxStringLen: 49}, LimitExceeded{"MaxStringLen", 49, Position{144, 8, 7}}},
+		{Limits{MaxArrayLen: 2}, LimitExceeded{"MaxArrayLen", 2, Position{311, 13, 23}}},
+	} {
+		_, err = ParseLimits(source, c.limits)
+		le, ok := err.(*LimitExceeded)
+		wt.True(ok, c.err.Limit, err)
+		if !ok {
+			continue
+		}
+		wt.Equal(*le, c.err)
+	}
+
+	_, err = ParseLimits([]byte("[[a]]\n[[a.b]]\n[[a.b]]\n[[a]]\n[[a.b]]\n[[a.b]]\n"), Limits{MaxArrayLen: 2})
+	wt.Nil(err)
+	_, err = ParseLimits([]byte("[[a]]\n[[a]]\n[[a]]\n"), Limits{MaxArrayLen: 2})
+	wt.Equal(err.Error(), "toml: 3:1: MaxArrayLen 2 exceeded")
+}
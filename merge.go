@@ -0,0 +1,195 @@
+package toml
+
+import (
+	"errors"
+)
+
+var KindConflict = errors.New("kind conflict")
+
+// MergePolicy 决定 Merge 如何处理数组和 ArrayOfTables.
+type MergePolicy int
+
+const (
+	MergeReplace MergePolicy = iota // overlay 替换 base, 默认值
+	MergeAppend                     // overlay 的元素追加到 base 的元素之后
+	MergeByKey                      // 仅用于 ArrayOfTables, 按 MergeOptions.Key 的值合并相同的 Table, Key 不能为空
+)
+
+// MergeOptions 是 Merge 的参数, 零值表示数组和 ArrayOfTables 都被 overlay 替换.
+type MergeOptions struct {
+	Array         MergePolicy // Array 及 typeArray, 不支持 MergeByKey
+	ArrayOfTables MergePolicy
+	Key           string // MergeByKey 时用来识别相同 Table 的 Key, 比如 "name"
+	// Strict 为 true 时, 同名元素 Kind 冲突会返回 KindConflict,
+	// 否则 overlay 优先, 比如值替换 TableName 时, 原 Table 的下属元素被删除.
+	Strict bool
+}
+
+/**
+Merge 把 overlay 合并到 base 的副本中并返回, base 和 overlay 都不会被修改.
+合并规则:
+	值: overlay 优先.
+	TableName: 递归合并, 由于 Toml 是扁平的, 这等同于合并全部 "TableName." 开头的元素.
+	数组: 由 opts.Array 决定替换或追加, 追加时必须满足 Value.Add 的要求.
+	ArrayOfTables: 由 opts.ArrayOfTables 决定替换, 追加或者按 Key 合并.
+	注释: overlay 中的元素有注释时使用 overlay 的注释, 否则保留 base 的注释.
+	位置: 被 overlay 替换的值使用 overlay 的 Position(), 参见 Loader.
+opts.Array 为 MergeByKey, 或者 opts.ArrayOfTables 为 MergeByKey 但是 opts.Key 为空时返回 NotSupported.
+多个文档可以依次合并:
+	tm, err := Merge(defaults, prod, opts)
+	tm, err = Merge(tm, local, opts)
+*/
+func Merge(base, overlay Toml, opts MergeOptions) (Toml, error) {
+	if opts.Array == MergeByKey || opts.ArrayOfTables == MergeByKey && opts.Key == "" {
+		return nil, NotSupported
+	}
+	tm := base.Clone()
+	if tm == nil {
+		tm = New()
+	}
+	if err := tm.merge(overlay, opts); err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+// 合并注释, src 有注释时才替换.
+func mergeComments(dst, src *Value) {
+	if len(src.multiComments) != 0 {
+		dst.multiComments = append(aString{}, src.multiComments...)
+	}
+	if src.eolComment != "" {
+		dst.eolComment = src.eolComment
+	}
+}
+
+func (p Toml) merge(src Toml, opts MergeOptions) error {
+	if id, ok := src[iD]; ok && id.Value != nil {
+		p.Id()
+		mergeComments(p[iD].Value, id.Value)
+	}
+
+	for _, k := range src.validKeys() {
+		it := src[k.key]
+
+		if err := p.mergeParent(k.key, opts.Strict); err != nil {
+			return err
+		}
+
+		d, ok := p[k.key]
+		if !ok || !d.IsValid() {
+			// 未声明的 TableName 只能被 TableName 覆盖
+			if it.kind != TableName && p.hasChildren(k.key) {
+				if opts.Strict {
+					return KindConflict
+				}
+				p.remove(k.key)
+			}
+			p[k.key] = it.Clone()
+			continue
+		}
+
+		switch {
+		case d.kind == TableName && it.kind == TableName:
+			mergeComments(d.Value, it.Value)
+
+		case d.kind == ArrayOfTables && it.kind == ArrayOfTables:
+			if err := d.mergeTables(it, opts); err != nil {
+				return err
+			}
+			mergeComments(d.Value, it.Value)
+
+		case d.kind < TableName && it.kind < TableName:
+			if opts.Array == MergeAppend && isArrayKind(d.kind) && isArrayKind(it.kind) {
+				nv := d.Value.Clone()
+				if err := nv.Add(it.Clone().elements()...); err == nil {
+					d.kind, d.v = nv.kind, nv.v
+					mergeComments(d.Value, it.Value)
+					continue
+				}
+			}
+			if d.kind != it.kind && opts.Strict {
+				return KindConflict
+			}
+			nv := it.Clone()
+			d.kind, d.v, d.loc = nv.kind, nv.v, nv.loc
+			mergeComments(d.Value, it.Value)
+
+		default:
+			if opts.Strict {
+				return KindConflict
+			}
+			p.remove(k.key)
+			p[k.key] = it.Clone()
+		}
+	}
+	return nil
+}
+
+// elements 返回数组元素, 用于 Add.
+func (p *Value) elements() []interface{} {
+	a, _ := p.v.([]*Value)
+	ai := make([]interface{}, len(a))
+	for i, v := range a {
+		ai[i] = v
+	}
+	return ai
+}
+
+// mergeParent 检查 key 的上级, 上级不能是值或者 ArrayOfTables.
+func (p Toml) mergeParent(key string, strict bool) error {
+	for i := 0; i < len(key); i++ {
+		if key[i] != '.' {
+			continue
+		}
+		it, ok := p[key[:i]]
+		if ok && it.IsValid() && it.kind != TableName {
+			if strict {
+				return KindConflict
+			}
+			p.remove(key[:i])
+		}
+	}
+	return nil
+}
+
+func (i Item) mergeTables(src Item, opts MergeOptions) error {
+	dst := i.TomlArray()
+	ts := src.TomlArray()
+
+	switch opts.ArrayOfTables {
+	case MergeAppend:
+		for _, tm := range ts {
+			dst = append(dst, tm.Clone())
+		}
+	case MergeByKey:
+		for _, tm := range ts {
+			j := dst.indexOf(opts.Key, tm[opts.Key])
+			if j == -1 {
+				dst = append(dst, tm.Clone())
+				continue
+			}
+			if err := dst[j].merge(tm, opts); err != nil {
+				return err
+			}
+		}
+	default:
+		dst = src.Clone().TomlArray()
+	}
+
+	i.v = dst
+	return nil
+}
+
+// indexOf 返回 key 的值与 it 相同的第一个 Table 的下标, 没有找到返回 -1.
+func (t TomlArray) indexOf(key string, it Item) int {
+	if !it.IsValue() {
+		return -1
+	}
+	for i, tm := range t {
+		if tm[key].Equal(it.Value, IgnoreComments) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,138 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+)
+
+const mergeBase = `
+title = "defaults"
+[server]
+host = "localhost" # default host
+port = 8080
+tags = ["a", "b"]
+[server.tls]
+enabled = false
+[[users]]
+name = "tom"
+role = "admin"
+[[users]]
+name = "jack"
+role = "guest"
+`
+
+const mergeOverlay = `
+# production
+[server]
+port = 80 # production port
+tags = ["c"]
+[server.tls]
+enabled = true
+cert = "/etc/cert.pem"
+[[users]]
+name = "jack"
+role = "dev"
+[[users]]
+name = "john"
+role = "guest"
+`
+
+func TestMerge(t *testing.T) {
+	wt := want.T(t)
+	base, err := Parse([]byte(mergeBase))
+	wt.Nil(err)
+	overlay, err := Parse([]byte(mergeOverlay))
+	wt.Nil(err)
+	source := base.String()
+
+	tm, err := Merge(base, overlay, MergeOptions{})
+	wt.Nil(err)
+	wt.Equal(base.String(), source) // base 未被修改
+
+	wt.Equal(tm.Get("title").String(), "defaults")
+	wt.Equal(tm.Get("server.host").String(), "localhost")
+	wt.Equal(tm.Get("server.host").Comment(), "# default host")
+	wt.Equal(tm.Get("server.port").Integer(), 80)
+	wt.Equal(tm.Get("server.port").Comment(), "# production port")
+	wt.Equal(tm.Get("server.tags").StringArray(), []string{"c"})
+	wt.Equal(tm.Get("server.tls.enabled").Boolean(), true)
+	wt.Equal(tm.Get("server.tls.cert").String(), "/etc/cert.pem")
+	wt.Equal(tm.Get("users").Len(), 2)
+	wt.Equal(tm.Get("users[0].name").String(), "jack")
+
+	tm, err = Merge(base, overlay, MergeOptions{Array: MergeAppend, ArrayOfTables: MergeAppend})
+	wt.Nil(err)
+	wt.Equal(tm.Get("server.tags").StringArray(), []string{"a", "b", "c"})
+	wt.Equal(tm.Get("users").Len(), 4)
+	wt.Equal(tm.Get("users[-1].name").String(), "john")
+
+	tm, err = Merge(base, overlay, MergeOptions{ArrayOfTables: MergeByKey, Key: "name"})
+	wt.Nil(err)
+	wt.Equal(tm.Get("users").Len(), 3)
+	wt.Equal(tm.Get("users[0].role").String(), "admin")
+	wt.Equal(tm.Get("users[1].name").String(), "jack")
+	wt.Equal(tm.Get("users[1].role").String(), "dev")
+	wt.Equal(tm.Get("users[2].name").String(), "john")
+
+	_, err = Merge(base, overlay, MergeOptions{ArrayOfTables: MergeByKey})
+	wt.Equal(err, NotSupported)
+	_, err = Merge(base, overlay, MergeOptions{Array: MergeByKey, Key: "name"})
+	wt.Equal(err, NotSupported)
+
+	ntm, err := Parse([]byte(tm.String()))
+	wt.Nil(err, tm.String())
+	wt.True(Equal(ntm, tm, IgnoreOrder), tm.String())
+}
+
+func TestMergeConflict(t *testing.T) {
+	wt := want.T(t)
+	base, err := Parse([]byte(mergeBase))
+	wt.Nil(err)
+	overlay := New()
+	wt.Nil(overlay.Set("server", "down"))
+	wt.Nil(overlay.Set("title", 1))
+	wt.Nil(overlay.Set("users.name", "tom"))
+
+	_, err = Merge(base, overlay, MergeOptions{Strict: true})
+	wt.Equal(err, KindConflict)
+
+	tm, err := Merge(base, overlay, MergeOptions{})
+	wt.Nil(err)
+	wt.Equal(tm.Get("server").String(), "down")
+	wt.Equal(tm.Get("title").Integer(), 1)
+	wt.False(tm.Get("server.port").IsValid())
+	wt.False(tm.Get("server.tls").IsValid())
+	wt.Equal(tm.Get("users").Kind(), TableName)
+	wt.Equal(tm.Get("users.name").String(), "tom")
+
+	// 类型不同的数组无法追加, overlay 优先
+	overlay = New()
+	wt.Nil(overlay.Set("server.tags", []int{1}))
+	tm, err = Merge(base, overlay, MergeOptions{Array: MergeAppend})
+	wt.Nil(err)
+	wt.Equal(tm.Get("server.tags").IntArray(), []int64{1})
+}
+
+func TestMergeImplicit(t *testing.T) {
+	wt := want.T(t)
+	base, err := Parse([]byte("[a.b]\nc = 1\n"))
+	wt.Nil(err)
+	overlay, err := Parse([]byte("a = 1\n"))
+	wt.Nil(err)
+
+	_, err = Merge(base, overlay, MergeOptions{Strict: true})
+	wt.Equal(err, KindConflict)
+
+	tm, err := Merge(base, overlay, MergeOptions{})
+	wt.Nil(err)
+	wt.Equal(tm.Keys(), []string{"a"})
+	wt.Equal(tm.Get("a").Integer(), 1)
+
+	// TableName 可以覆盖未声明的 TableName
+	overlay, err = Parse([]byte("[a]\nd = 2\n"))
+	wt.Nil(err)
+	tm, err = Merge(base, overlay, MergeOptions{Strict: true})
+	wt.Nil(err)
+	wt.Equal(tm.Get("a.b.c").Integer(), 1)
+	wt.Equal(tm.Get("a.d").Integer(), 2)
+}
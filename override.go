@@ -0,0 +1,171 @@
+package toml
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AmbiguousEnv 表示多个访问路径对应同一个环境变量名称, 比如 a.b_c 和 a_b.c 都对应 A_B_C.
+var AmbiguousEnv = errors.New("ambiguous environment variable")
+
+/**
+Overlay 用环境变量和命令行参数覆盖 Toml 中的值, 常用于容器中的配置.
+字符串按照目标的 Kind 使用 Value.SetAs 转换, 包括数组.
+
+	o := toml.Overlay{Prefix: "APP_", Kinds: s.Paths()} // s 是 *schema.Schema
+	errs := o.ApplyEnv(tm, nil)
+	errs = append(errs, o.ApplyFlags(tm, flag.CommandLine)...)
+*/
+type Overlay struct {
+	// 环境变量的前缀, 比如 "APP_", 只处理以它开头的环境变量.
+	// 为空时处理全部环境变量, 但是忽略没有对应访问路径的环境变量, 比如 HOME.
+	Prefix string
+	// Kinds 是允许建立的访问路径及其 Kind, tm 中不存在的值只有在这里才会被建立.
+	Kinds map[string]Kind
+}
+
+// OverrideError 是无法覆盖的环境变量或者命令行参数.
+type OverrideError struct {
+	Source string // 环境变量的名称, 或者 "-" 开头的命令行参数名称
+	Path   string // 访问路径, 没有对应的访问路径时为 "", AmbiguousEnv 时为全部对应的访问路径, 以 ", " 分隔
+	Value  string
+	Err    error // NotFound, NotSupported, AmbiguousEnv 或者转换时的错误
+}
+
+func (e OverrideError) String() string {
+	s := "toml: " + e.Source + "=" + strconv.Quote(e.Value) + ": "
+	if e.Path != "" {
+		s += e.Path + ": "
+	}
+	return s + e.Err.Error()
+}
+
+func (e OverrideError) Error() string {
+	return e.String()
+}
+
+/**
+EnvName 返回访问路径 path 对应的环境变量名称, 不含前缀.
+"." 和 "-" 替换为 "_" 并转为大写, 比如 servers.alpha.ip 为 SERVERS_ALPHA_IP.
+*/
+func EnvName(path string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_", `"`, "").Replace(path))
+}
+
+/**
+ApplyEnv 用 environ 中以 Prefix 开头的环境变量覆盖 tm, environ 为 nil 时使用 os.Environ().
+去掉 Prefix 后的名称与 tm 中的值或者 Kinds 中的访问路径的 EnvName 对应,
+不支持 ArrayOfTables 中的值. 返回全部无法覆盖的环境变量, 按名称排序.
+多个访问路径对应同一个名称时不覆盖任何一个, 返回 AmbiguousEnv.
+Prefix 为空时没有对应访问路径的环境变量被忽略, 否则返回 NotFound.
+*/
+func (o Overlay) ApplyEnv(tm Toml, environ []string) []OverrideError {
+	if environ == nil {
+		environ = os.Environ()
+	}
+
+	paths := map[string][]string{}
+	add := func(path string) {
+		name := EnvName(path)
+		for _, p := range paths[name] {
+			if p == path {
+				return
+			}
+		}
+		paths[name] = append(paths[name], path)
+	}
+	for path := range o.Kinds {
+		add(path)
+	}
+	for _, k := range tm.validKeys() {
+		if k.kind < TableName {
+			add(JoinPath(strings.Split(k.key, ".")))
+		}
+	}
+
+	var envs []string
+	for _, env := range environ {
+		if strings.HasPrefix(env, o.Prefix) && strings.IndexByte(env, '=') != -1 {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+
+	var errs []OverrideError
+	for _, env := range envs {
+		pos := strings.IndexByte(env, '=')
+		name, value := env[:pos], env[pos+1:]
+		ps := paths[name[len(o.Prefix):]]
+		switch {
+		case len(ps) == 0:
+			if o.Prefix != "" {
+				errs = append(errs, OverrideError{name, "", value, NotFound})
+			}
+			continue
+		case len(ps) > 1:
+			sort.Strings(ps)
+			errs = append(errs, OverrideError{name, strings.Join(ps, ", "), value, AmbiguousEnv})
+			continue
+		}
+		path := ps[0]
+		if err := o.set(tm, path, value); err != nil {
+			errs = append(errs, OverrideError{name, path, value, err})
+		}
+	}
+	return errs
+}
+
+/**
+ApplyFlags 用 fs 中已经设置的命令行参数覆盖 tm, 参数名称是访问路径, 比如:
+
+	fs.String("servers.alpha.ip", "", "IP of alpha")
+	fs.String("products[0].name", "", "")
+
+不是访问路径或者 tm 和 Kinds 中都没有的参数被忽略, 比如 -config.
+返回全部无法覆盖的参数, 按名称排序.
+*/
+func (o Overlay) ApplyFlags(tm Toml, fs *flag.FlagSet) []OverrideError {
+	var errs []OverrideError
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := o.Kinds[f.Name]; !ok && !tm.Get(f.Name).IsValid() {
+			return
+		}
+		value := f.Value.String()
+		if err := o.set(tm, f.Name, value); err != nil {
+			errs = append(errs, OverrideError{"-" + f.Name, f.Name, value, err})
+		}
+	})
+	return errs
+}
+
+// set 把 value 转换为 path 对应的值的 Kind, 值不存在时使用 Kinds 中的 Kind 建立.
+func (o Overlay) set(tm Toml, path, value string) error {
+	if it, ok := tm.Lookup(path); ok {
+		if it.kind >= TableName {
+			return NotSupported
+		}
+		nv := &Value{}
+		if err := nv.SetAs(value, it.kind); err != nil {
+			return err
+		}
+		// Array 转换后可能是 typeArray
+		if it.kind == Array {
+			it.kind = nv.kind
+		}
+		return it.replace(nv)
+	}
+
+	kind, ok := o.Kinds[path]
+	if !ok {
+		return NotFound
+	}
+	nv := &Value{}
+	if err := nv.SetAs(value, kind); err != nil {
+		return err
+	}
+	return tm.Set(path, nv)
+}
@@ -0,0 +1,110 @@
+package toml
+
+import (
+	"flag"
+	"github.com/achun/testing-want"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	wt := want.T(t)
+	wt.Equal(EnvName("servers.alpha.ip"), "SERVERS_ALPHA_IP")
+	wt.Equal(EnvName("database.connection_max"), "DATABASE_CONNECTION_MAX")
+	wt.Equal(EnvName(`"my-app".port`), "MY_APP_PORT")
+}
+
+func TestOverlayApplyEnv(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	o := Overlay{
+		Prefix: "APP_",
+		Kinds:  map[string]Kind{"database.user": String, "cache.ttl": Integer},
+	}
+	errs := o.ApplyEnv(tm, []string{
+		"HOME=/root",
+		"APP_SERVERS_ALPHA_IP=10.0.0.9",
+		"APP_DATABASE_PORTS=9001, 9002",
+		"APP_DATABASE_CONNECTION_MAX=100",
+		"APP_DATABASE_ENABLED=yes",
+		"APP_DATABASE_USER=admin",
+		"APP_CACHE_TTL=60",
+		"APP_NOTHING=1",
+		"APP_CLIENTS_DATA=[[\"a\"], [1]]",
+	})
+
+	wt.Equal(tm.Get("servers.alpha.ip").String(), "10.0.0.9")
+	wt.Equal(tm.Get("database.ports").IntArray(), []int64{9001, 9002})
+	wt.Equal(tm.Get("database.connection_max").Integer(), 100)
+	wt.Equal(tm.Get("database.enabled").Boolean(), true)
+	wt.Equal(tm.Get("database.user").String(), "admin")
+	wt.Equal(tm.Get("cache").Kind(), TableName)
+	wt.Equal(tm.Get("cache.ttl").Integer(), 60)
+	wt.Equal(tm.Get("clients.data").String(), `[["a"], [1]]`)
+
+	wt.Equal(len(errs), 2)
+	if len(errs) == 2 {
+		wt.Equal(errs[0].Source, "APP_DATABASE_ENABLED")
+		wt.Equal(errs[0].Path, "database.enabled")
+		wt.Equal(errs[1].Error(), `toml: APP_NOTHING="1": not found`)
+	}
+}
+
+func TestOverlayApplyEnvAmbiguous(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte("[a]\nb_c = 1\n[a_b]\nc = 2\n[d]\ne = 3\n"))
+	wt.Nil(err)
+
+	// 没有 Prefix 时忽略无关的环境变量
+	errs := Overlay{}.ApplyEnv(tm, []string{"HOME=/root", "A_B_C=9", "D_E=4"})
+	wt.Equal(tm.Get("a.b_c").Integer(), 1)
+	wt.Equal(tm.Get("a_b.c").Integer(), 2)
+	wt.Equal(tm.Get("d.e").Integer(), 4)
+
+	wt.Equal(len(errs), 1)
+	if len(errs) == 1 {
+		wt.Equal(errs[0].Path, "a.b_c, a_b.c")
+		wt.Equal(errs[0].Err, AmbiguousEnv)
+		wt.Equal(errs[0].Error(), `toml: A_B_C="9": a.b_c, a_b.c: ambiguous environment variable`)
+	}
+}
+
+func TestOverlayApplyFlags(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("config", "", "")
+	fs.String("servers.beta.ip", "", "")
+	fs.String("products[1].sku", "", "")
+	fs.String("database.ports", "", "")
+	fs.Bool("database.enabled", true, "")
+	fs.Int("cache.ttl", 0, "")
+	fs.String("title", "", "")
+	wt.Nil(fs.Parse([]string{
+		"-config", "app.toml",
+		"-servers.beta.ip", "10.0.0.8",
+		"-products[1].sku", "42",
+		"-database.ports", "[1, 2]",
+		"-database.enabled=false",
+		"-cache.ttl", "60",
+	}))
+
+	errs := Overlay{}.ApplyFlags(tm, fs)
+	wt.Equal(len(errs), 0)
+	wt.Equal(tm.Get("servers.beta.ip").String(), "10.0.0.8")
+	wt.Equal(tm.Get("products[1].sku").Integer(), 42)
+	wt.Equal(tm.Get("database.ports").IntArray(), []int64{1, 2})
+	wt.Equal(tm.Get("database.enabled").Boolean(), false)
+	wt.False(tm.Get("cache.ttl").IsValid())
+	wt.Equal(tm.Get("title").String(), "TOML Example")
+
+	errs = Overlay{Kinds: map[string]Kind{"cache.ttl": Datetime}}.ApplyFlags(tm, fs)
+	wt.Equal(len(errs), 1)
+	if len(errs) == 1 {
+		wt.Equal(errs[0].Source, "-cache.ttl")
+		wt.Equal(errs[0].Path, "cache.ttl")
+	}
+}
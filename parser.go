@@ -34,29 +34,33 @@ const (
 	BOM = 0xFEFF // UTF-8 encoded byte order mark
 )
 
+/**
+Token 是词法单元的种类, 由 Tokenize 和 Tokenizer 提供, 是稳定的 API.
+常量的值和次序不会改变, 新的 Token 只会追加在后面.
+*/
 type Token uint
 
 // don't change order
 const (
-	tokenEOF Token = iota
-	tokenString
-	tokenInteger
-	tokenFloat
-	tokenBoolean
-	tokenDatetime
-	tokenWhitespace
-	tokenComment
-	tokenTableName
-	tokenArrayOfTables
-	tokenNewLine
-	tokenKey
-	tokenEqual
-	tokenArrayLeftBrack
-	tokenArrayRightBrack
-	tokenComma
-	tokenError
-	tokenRuneError
-	tokenNothing
+	TokenEOF             Token = iota // 文档结束, 内容为空
+	TokenString                       // 基本字符串, 含引号
+	TokenInteger                      // 整数
+	TokenFloat                        // 浮点数
+	TokenBoolean                      // true 或 false
+	TokenDatetime                     // 1979-05-27T07:32:00Z
+	TokenWhitespace                   // 空格和制表符
+	TokenComment                      // "#" 开始到行尾
+	TokenTableName                    // [name]
+	TokenArrayOfTables                // [[name]]
+	TokenNewLine                      // 换行
+	TokenKey                          // Key-Value 中的 Key
+	TokenEqual                        // =
+	TokenArrayLeftBrack               // 数组的 [
+	TokenArrayRightBrack              // 数组的 ]
+	TokenComma                        // 数组的 ,
+	TokenError                        // 错误, 内容为错误信息
+	TokenRuneError                    // 非法的 UTF-8 编码
+	tokenNothing                      // 内部使用, 不会出现在输出中
 )
 
 func (t Token) String() string {
@@ -89,6 +93,7 @@ var tokensName = [...]string{
 	"Nothing",
 }
 
+// TokenHandler 接收 Token 和去掉首尾空白的内容, TokenError 的内容为错误信息.
 type TokenHandler func(Token, string) error
 
 type parser interface {
@@ -110,6 +115,7 @@ type parse struct {
 	Scanner
 	err      error
 	handler  TokenHandler
+	raw      string // 最后一个 Token 的原始内容
 	next     bool
 	testMode bool // 测试模式允许不完整的 stage
 }
@@ -138,7 +144,7 @@ func (p *parse) Keep() {
 
 func (p *parse) Err(msg string) {
 	p.err = errors.New(msg)
-	p.Token(tokenError)
+	p.Token(TokenError)
 }
 
 func (p *parse) NotMatch(token ...Token) {
@@ -154,22 +160,22 @@ func (p *parse) NotMatch(token ...Token) {
 	}
 
 	p.err = errors.New(msg)
-	p.Token(tokenError)
+	p.Token(TokenError)
 }
 
 func (p *parse) Invalid(token Token) {
 	p.err = errors.New("invalid " + tokensName[token])
-	p.Token(tokenError)
+	p.Token(TokenError)
 }
 
 func (p *parse) Unexpected(token Token) {
 	p.err = errors.New("unexpected token " + tokensName[token])
-	p.Token(tokenError)
+	p.Token(TokenError)
 }
 
 func (p *parse) Token(token Token) (err error) {
 	var str string
-	if token == tokenError {
+	if token == TokenError {
 		if p.err == nil {
 			p.err = errors.New("invalid format.")
 		}
@@ -177,7 +183,8 @@ func (p *parse) Token(token Token) (err error) {
 		str = err.Error()
 	} else {
 		str = p.Scanner.Fetch(p.next)
-		if token != tokenEOF && token != tokenWhitespace {
+		p.raw = str
+		if token != TokenEOF && token != TokenWhitespace {
 			str = strings.TrimSpace(str)
 		}
 	}
@@ -185,7 +192,7 @@ func (p *parse) Token(token Token) (err error) {
 	if p.handler == nil {
 		fmt.Println(token.String(), str)
 	} else {
-		if token == tokenError {
+		if token == TokenError {
 			p.handler(token, str)
 			if err == nil {
 				err = errors.New("tokenError")
@@ -205,19 +212,19 @@ func (p *parse) Handler(h TokenHandler) {
 // tokens
 func itsWhitespace(r rune, flag int, maybe bool) (Status, Token) {
 	if maybe && flag == 0 {
-		return SNot, tokenWhitespace
+		return SNot, TokenWhitespace
 	}
 	if isWhitespace(r) {
 		return SMaybe, 1
 	}
 	if flag == 1 {
-		return SYesKeep, tokenWhitespace
+		return SYesKeep, TokenWhitespace
 	}
-	return SNot, tokenWhitespace
+	return SNot, TokenWhitespace
 }
 func itsComment(r rune, flag int, maybe bool) (Status, Token) {
 	if maybe && flag == 0 {
-		return SNot, tokenComment
+		return SNot, TokenComment
 	}
 
 	switch flag {
@@ -227,18 +234,18 @@ func itsComment(r rune, flag int, maybe bool) (Status, Token) {
 		}
 	case 1:
 		if isEOF(r) {
-			return SYes, tokenComment
+			return SYes, TokenComment
 		}
 		if isNewLine(r) {
-			return SYesKeep, tokenComment
+			return SYesKeep, TokenComment
 		}
 		return SMaybe, 1
 	}
-	return SNot, tokenComment
+	return SNot, TokenComment
 }
 func itsString(r rune, flag int, maybe bool) (Status, Token) {
 	if maybe && flag == 0 {
-		return SNot, tokenString
+		return SNot, TokenString
 	}
 
 	switch flag {
@@ -246,14 +253,14 @@ func itsString(r rune, flag int, maybe bool) (Status, Token) {
 		if r == '"' {
 			return SMaybe, 2
 		}
-		return SNot, tokenString
+		return SNot, TokenString
 	case 1: // skip
 		if !isNewLine(r) {
 			return SMaybe, 2
 		}
 	case 2:
 		if r == '"' {
-			return SYes, tokenString
+			return SYes, TokenString
 		}
 		if r == '\\' {
 			return SMaybe, 1
@@ -262,13 +269,13 @@ func itsString(r rune, flag int, maybe bool) (Status, Token) {
 			return SMaybe, 2
 		}
 	}
-	return SInvalid, tokenString
+	return SInvalid, TokenString
 }
 
 // 要求在 itsFlaot 的前面
 func itsInteger(r rune, flag int, maybe bool) (Status, Token) {
 	if maybe && flag == 0 {
-		return SNot, tokenInteger
+		return SNot, TokenInteger
 	}
 
 	switch flag {
@@ -288,10 +295,10 @@ func itsInteger(r rune, flag int, maybe bool) (Status, Token) {
 			return SMaybe, 2
 		}
 		if isSuffixOfValue(r) {
-			return SYesKeep, tokenInteger
+			return SYesKeep, TokenInteger
 		}
 	}
-	return SNot, tokenInteger
+	return SNot, TokenInteger
 }
 
 func itsFloat(r rune, flag int, maybe bool) (Status, Token) {
@@ -320,16 +327,16 @@ func itsFloat(r rune, flag int, maybe bool) (Status, Token) {
 		if is09(r) {
 			return SMaybe, 4
 		}
-		return SInvalid, tokenFloat
+		return SInvalid, TokenFloat
 	case 4:
 		if is09(r) {
 			return SMaybe, 4
 		}
 		if isSuffixOfValue(r) {
-			return SYesKeep, tokenFloat
+			return SYesKeep, TokenFloat
 		}
 	}
-	return SNot, tokenFloat
+	return SNot, TokenFloat
 }
 
 func itsBoolean(r rune, flag int, maybe bool) (Status, Token) {
@@ -337,7 +344,7 @@ func itsBoolean(r rune, flag int, maybe bool) (Status, Token) {
 	switch flag {
 	case 0:
 		if maybe {
-			return SNot, tokenBoolean
+			return SNot, TokenBoolean
 		}
 		if r == 't' {
 			return SMaybe, 1
@@ -351,10 +358,10 @@ func itsBoolean(r rune, flag int, maybe bool) (Status, Token) {
 		}
 	case 4, 9:
 		if isSuffixOfValue(r) {
-			return SYesKeep, tokenBoolean
+			return SYesKeep, TokenBoolean
 		}
 	}
-	return SNot, tokenBoolean
+	return SNot, TokenBoolean
 }
 func itsDatetime(r rune, flag int, maybe bool) (Status, Token) {
 	const layout = "0000-00-00T00:00:00Z"
@@ -363,13 +370,13 @@ func itsDatetime(r rune, flag int, maybe bool) (Status, Token) {
 			return SMaybe, Token(flag + 1)
 		}
 		if flag <= 4 {
-			return SNot, tokenDatetime
+			return SNot, TokenDatetime
 		}
 	}
 	if flag == 20 && isSuffixOfValue(r) {
-		return SYesKeep, tokenDatetime
+		return SYesKeep, TokenDatetime
 	}
-	return SInvalid, tokenDatetime
+	return SInvalid, TokenDatetime
 }
 
 // [ASCII] http://en.wikipedia.org/wiki/ASCII#ASCII_printable_characters
@@ -381,22 +388,22 @@ func itsTableName(r rune, flag int, maybe bool) (Status, Token) {
 		}
 	case 1:
 		if r == '[' {
-			return SNot, tokenTableName
+			return SNot, TokenTableName
 		}
 		if isNewLine(r) || isWhitespace(r) || r == ']' || r == '.' {
-			return SInvalid, tokenTableName
+			return SInvalid, TokenTableName
 		}
 		return SMaybe, 2
 	case 2:
 		if isNewLine(r) || isWhitespace(r) {
-			return SInvalid, tokenTableName
+			return SInvalid, TokenTableName
 		}
 		if r != ']' {
 			return SMaybe, 2
 		}
-		return SYes, tokenTableName
+		return SYes, TokenTableName
 	}
-	return SNot, tokenTableName
+	return SNot, TokenTableName
 }
 
 func itsArrayOfTables(r rune, flag int, maybe bool) (Status, Token) {
@@ -407,12 +414,12 @@ func itsArrayOfTables(r rune, flag int, maybe bool) (Status, Token) {
 		}
 	case 2:
 		if isNewLine(r) || isWhitespace(r) || r == ']' || r == '.' {
-			return SInvalid, tokenArrayOfTables
+			return SInvalid, TokenArrayOfTables
 		}
 		return SMaybe, 3
 	case 3:
 		if isNewLine(r) || isWhitespace(r) {
-			return SInvalid, tokenArrayOfTables
+			return SInvalid, TokenArrayOfTables
 		}
 		if r != ']' {
 			return SMaybe, 3
@@ -420,76 +427,76 @@ func itsArrayOfTables(r rune, flag int, maybe bool) (Status, Token) {
 		return SMaybe, 4
 	case 4:
 		if r != ']' {
-			return SInvalid, tokenArrayOfTables
+			return SInvalid, TokenArrayOfTables
 		}
-		return SYes, tokenArrayOfTables
+		return SYes, TokenArrayOfTables
 	}
-	return SNot, tokenArrayOfTables
+	return SNot, TokenArrayOfTables
 }
 func itsNewLine(r rune, flag int, maybe bool) (Status, Token) {
 	if flag == 0 && isNewLine(r) {
-		return SYes, tokenNewLine
+		return SYes, TokenNewLine
 	}
-	return SNot, tokenNewLine
+	return SNot, TokenNewLine
 }
 
 func itsKey(r rune, flag int, maybe bool) (Status, Token) {
 	if maybe && flag == 0 {
-		return SNot, tokenKey
+		return SNot, TokenKey
 	}
 	switch flag {
 	case 0:
 		return SMaybe, 1
 	case 1:
 		if isNewLine(r) || isEOF(r) {
-			return SInvalid, tokenKey
+			return SInvalid, TokenKey
 		}
 		if r == '=' || isWhitespace(r) {
-			return SYesKeep, tokenKey
+			return SYesKeep, TokenKey
 		}
 		return SMaybe, 1
 	}
-	return SNot, tokenKey
+	return SNot, TokenKey
 }
 func itsEqual(r rune, flag int, maybe bool) (Status, Token) {
 	if maybe && flag == 0 {
-		return SNot, tokenEqual
+		return SNot, TokenEqual
 	}
 	if r == '=' {
-		return SYes, tokenEqual
+		return SYes, TokenEqual
 	}
-	return SNot, tokenEqual
+	return SNot, TokenEqual
 }
 
 func itsComma(r rune, flag int, maybe bool) (Status, Token) {
 	if !maybe && r == ',' {
-		return SYes, tokenComma
+		return SYes, TokenComma
 	}
-	return SNot, tokenComma
+	return SNot, TokenComma
 }
 
 func itsArrayLeftBrack(r rune, flag int, maybe bool) (Status, Token) {
 	if !maybe && r == '[' {
-		return SYes, tokenArrayLeftBrack
+		return SYes, TokenArrayLeftBrack
 	}
-	return SNot, tokenArrayLeftBrack
+	return SNot, TokenArrayLeftBrack
 }
 func itsArrayRightBrack(r rune, flag int, maybe bool) (Status, Token) {
 	if !maybe && r == ']' {
-		return SYes, tokenArrayRightBrack
+		return SYes, TokenArrayRightBrack
 	}
-	return SNot, tokenArrayRightBrack
+	return SNot, TokenArrayRightBrack
 }
 
 func itsEOF(r rune, flag int, maybe bool) (Status, Token) {
 	if r == EOF {
-		return SYes, tokenEOF
+		return SYes, TokenEOF
 	}
-	return SNot, tokenEOF
+	return SNot, TokenEOF
 }
 
 func itsSNot(r rune, flag int, maybe bool) (Status, Token) {
-	return SNot, tokenEOF
+	return SNot, TokenEOF
 }
 
 func isEOF(r rune) bool {
@@ -1,6 +1,8 @@
 package toml
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/achun/testing-want"
 	"io/ioutil"
 	"testing"
@@ -49,7 +51,7 @@ func assertParse(wt want.Want, str string, outs ...string) {
 		last = outs[l-1]
 	}
 	p.Handler(func(token Token, str string) (err error) {
-		if tokenWhitespace == token || token == tokenNewLine || token == tokenEOF {
+		if TokenWhitespace == token || token == TokenNewLine || token == TokenEOF {
 			return
 		}
 
@@ -354,3 +356,69 @@ func TestFile(t *testing.T) {
 		`Comment # TOML document`,
 	)
 }
+
+// synthetic 生成约 size 字节的 TOML 文档, 包含各种 Token.
+func synthetic(size int) []byte {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < size; i++ {
+		fmt.Fprintf(&buf, "# table %d\n[server%d]\n", i, i)
+		fmt.Fprintf(&buf, "name = \"server \\\"%d\\\"\" # name\n", i)
+		fmt.Fprintf(&buf, "port = %d\nratio = %d.5\nenabled = true\n", 8000+i, i)
+		fmt.Fprintf(&buf, "created = 1979-05-27T07:32:00Z\n")
+		fmt.Fprintf(&buf, "ports = [ %d, %d, %d ]\n", i, i+1, i+2)
+		fmt.Fprintf(&buf, "data = [ [\"a\", \"b\"], [1.5, 2.5] ]\n\n")
+		fmt.Fprintf(&buf, "[[server%d.hosts]]\nip = \"10.0.0.%d\"\n\n", i, i%256)
+	}
+	return buf.Bytes()
+}
+
+func benchmarkTokens(b *testing.B, source []byte) {
+	b.SetBytes(int64(len(source)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := &parse{Scanner: NewScanner(source)}
+		p.Handler(func(Token, string) error { return nil })
+		p.Run()
+		if p.err != nil {
+			b.Fatal(p.err)
+		}
+	}
+}
+
+func benchmarkParse(b *testing.B, source []byte) {
+	b.SetBytes(int64(len(source)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(source); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTokensHardExample(b *testing.B) {
+	source, err := ioutil.ReadFile("tests/hard_example.toml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkTokens(b, source)
+}
+
+func BenchmarkTokensSynthetic(b *testing.B) {
+	benchmarkTokens(b, synthetic(2<<20))
+}
+
+func BenchmarkParseHardExample(b *testing.B) {
+	source, err := ioutil.ReadFile("tests/hard_example.toml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkParse(b, source)
+}
+
+func BenchmarkParseSynthetic64K(b *testing.B) {
+	benchmarkParse(b, synthetic(64<<10))
+}
+
+func BenchmarkParseSynthetic2M(b *testing.B) {
+	benchmarkParse(b, synthetic(2<<20))
+}
@@ -0,0 +1,563 @@
+package toml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var TestFailed = errors.New("test failed")
+
+/**
+ApplyPatch 把 JSON Patch (RFC 6902) 或 JSON Merge Patch (RFC 7396) 应用到 tm.
+patch 是 JSON 数组时按 JSON Patch 处理, 是 JSON 对象时按 Merge Patch 处理.
+
+JSON Pointer 被映射为访问路径:
+	/servers/alpha/ip  ->  servers.alpha.ip
+	/products/1/name   ->  products[1].name
+	/database/ports/-  ->  database.ports[3]   // "-" 表示追加
+JSON 的对象对应 TableName, 元素都是对象的数组对应 ArrayOfTables,
+其他数组转换为 typeArray 或 Array, 必须满足 Value.Add 的要求, 比如不能混合 Integer 和 String.
+JSON 字符串写入 Datetime 或 DatetimeArray 时按 RFC3339 转换.
+JSON 中的 null 只能用于 Merge Patch 的删除操作.
+
+值被替换时保留原有的注释, 未受影响的元素保持不变.
+整个 patch 是原子的, 任何一步失败 tm 都不会被修改, 成功时 tm 的元素被替换为修改后的副本.
+*/
+func ApplyPatch(tm Toml, patch []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(patch))
+	dec.UseNumber()
+
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return err
+	}
+
+	if tm == nil {
+		return NotSupported
+	}
+
+	nt := tm.Clone()
+	var err error
+
+	switch v := x.(type) {
+	case []interface{}:
+		err = nt.jsonPatch(v)
+	case map[string]interface{}:
+		err = nt.mergePatch("", v)
+	default:
+		err = NotSupported
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for key := range tm {
+		delete(tm, key)
+	}
+	for key, it := range nt {
+		tm[key] = it
+	}
+	return nil
+}
+
+// RFC 6902
+func (p Toml) jsonPatch(ops []interface{}) error {
+	for _, x := range ops {
+		op, ok := x.(map[string]interface{})
+		if !ok {
+			return InValidFormat
+		}
+
+		name, _ := op["op"].(string)
+		ptr, ok := op["path"].(string)
+		if !ok {
+			return InValidFormat
+		}
+		path, err := p.pointerPath(ptr)
+		if err != nil {
+			return err
+		}
+
+		value, hasValue := op["value"]
+		if (name == "add" || name == "replace" || name == "test") && !hasValue {
+			return InValidFormat
+		}
+
+		var from string
+		if name == "move" || name == "copy" {
+			ptr, ok = op["from"].(string)
+			if !ok {
+				return InValidFormat
+			}
+			if from, err = p.pointerPath(ptr); err != nil {
+				return err
+			}
+		}
+
+		switch name {
+		case "add":
+			err = p.add(path, value)
+		case "remove":
+			err = p.Delete(path)
+		case "replace":
+			if !p.exists(path) {
+				return NotFound
+			}
+			err = p.add(path, value)
+			if err == nil && isIndexPath(path) {
+				err = p.Delete(nextIndexPath(path))
+			}
+		case "move":
+			if from == path {
+				break
+			}
+			if strings.HasPrefix(path, from+".") || strings.HasPrefix(path, from+"[") {
+				return InvalidPath
+			}
+			if !isIndexPath(from) && !isIndexPath(path) && !p.exists(path) {
+				err = p.Move(from, path)
+				break
+			}
+			if value, err = p.valueAt(from); err == nil {
+				if err = p.Delete(from); err == nil {
+					err = p.add(path, value)
+				}
+			}
+		case "copy":
+			if value, err = p.valueAt(from); err == nil {
+				err = p.add(path, value)
+			}
+		case "test":
+			var got interface{}
+			if got, err = p.valueAt(path); err == nil &&
+				!reflect.DeepEqual(jsonNormal(got), jsonNormal(value)) {
+				err = TestFailed
+			}
+		default:
+			err = NotSupported
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RFC 7396
+func (p Toml) mergePatch(path string, patch map[string]interface{}) error {
+	keys := make([]string, 0, len(patch))
+	for k := range patch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sub := quoteKey(k)
+		if path != "" {
+			sub = path + "." + sub
+		}
+		switch v := patch[k].(type) {
+		case nil:
+			if err := p.Delete(sub); err != nil && err != NotFound {
+				return err
+			}
+		case map[string]interface{}:
+			if _, ok := p.LookupTable(sub); !ok && p.exists(sub) {
+				if err := p.Delete(sub); err != nil {
+					return err
+				}
+			}
+			if err := p.makeTable(sub); err != nil {
+				return err
+			}
+			if err := p.mergePatch(sub, v); err != nil {
+				return err
+			}
+		default:
+			if err := p.put(sub, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/**
+pointerPath 把 JSON Pointer 转换为访问路径.
+ArrayOfTables 和数组中的 token 必须是下标, "-" 被转换为数组长度, 表示追加.
+*/
+func (p Toml) pointerPath(pointer string) (string, error) {
+	if pointer == "" {
+		return "", NotSupported
+	}
+	if pointer[0] != '/' {
+		return "", InvalidPath
+	}
+
+	var (
+		path string
+		key  string // 当前 Toml 中的 key
+		v    *Value // 当前所在的数组
+		leaf bool   // 已经到达数组中的值
+	)
+
+	tm := p
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		tok = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+
+		if leaf {
+			return "", NotFound
+		}
+
+		var it Item
+		if v == nil && key != "" {
+			it = tm[key]
+		}
+
+		if v != nil || isArrayKind(it.Kind()) || it.Kind() == ArrayOfTables {
+			size := it.Len()
+			if v != nil {
+				size = v.Len()
+			}
+			n, err := pointerIndex(tok, size)
+			if err != nil {
+				return "", err
+			}
+			path += "[" + strconv.Itoa(n) + "]"
+
+			switch {
+			case v != nil:
+				v = v.Index(n)
+			case it.kind == ArrayOfTables:
+				tm, key = it.Table(n), ""
+				continue
+			default:
+				v = it.Value.Index(n)
+			}
+
+			if v == nil || !isArrayKind(v.kind) {
+				leaf = true
+			}
+			continue
+		}
+
+		if tok == "-" {
+			return "", NotFound
+		}
+
+		if key == "" {
+			key = tok
+		} else {
+			key += "." + tok
+		}
+
+		if path == "" {
+			path = quoteKey(tok)
+		} else {
+			path += "." + quoteKey(tok)
+		}
+	}
+	return path, nil
+}
+
+func pointerIndex(tok string, size int) (int, error) {
+	if tok == "-" {
+		return size, nil
+	}
+	n, err := strconv.Atoi(tok)
+	if err != nil || n < 0 || n > size || tok != strconv.Itoa(n) {
+		return 0, InvalidPath
+	}
+	return n, nil
+}
+
+func isIndexPath(path string) bool {
+	return strings.HasSuffix(path, "]")
+}
+
+// nextIndexPath 返回下标加一的路径, 用于 replace 删除被替换的元素.
+func nextIndexPath(path string) string {
+	i := strings.LastIndex(path, "[")
+	n, _ := strconv.Atoi(path[i+1 : len(path)-1])
+	return path[:i+1] + strconv.Itoa(n+1) + "]"
+}
+
+func (p Toml) exists(path string) bool {
+	segs, err := splitPath(path)
+	if err != nil {
+		return false
+	}
+	_, err = p.lookup(segs)
+	return err == nil
+}
+
+/**
+add 实现 JSON Patch 的 add 操作.
+路径以下标结尾时插入到数组或 ArrayOfTables 中, 否则写入或替换 path.
+*/
+func (p Toml) add(path string, x interface{}) error {
+	if !isIndexPath(path) {
+		return p.put(path, x)
+	}
+
+	segs, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+
+	last := &segs[len(segs)-1]
+	n := last.idx[len(last.idx)-1]
+	last.idx = last.idx[:len(last.idx)-1]
+
+	t, err := p.lookup(segs)
+	if err != nil {
+		return err
+	}
+
+	if t.v == nil {
+		return NotFound
+	}
+
+	if t.v.kind == ArrayOfTables {
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			return NotSupported
+		}
+		aot := t.v.v.(TomlArray)
+		if n > len(aot) {
+			return NotFound
+		}
+		elem := New()
+		if err = elem.mergePatch("", m); err != nil {
+			return err
+		}
+		aot = append(aot, nil)
+		copy(aot[n+1:], aot[n:])
+		aot[n] = elem
+		t.v.v = aot
+		return nil
+	}
+
+	if !isArrayKind(t.v.kind) {
+		return NotSupported
+	}
+	if n > t.v.Len() {
+		return NotFound
+	}
+
+	ev, err := toValue(jsonValue(x, t.v.kind-StringArray+String))
+	if err != nil {
+		return err
+	}
+
+	// 借助 Add 检查 typeArray 的一致性
+	nv := t.v.Clone()
+	if err = nv.Add(ev); err != nil {
+		return err
+	}
+
+	a := nv.v.([]*Value)
+	copy(a[n+1:], a[n:len(a)-1])
+	a[n] = ev
+	t.v.kind, t.v.v = nv.kind, a
+	return nil
+}
+
+/**
+put 把 JSON 值 x 写入 path, 已经存在的元素被替换, 保留原有的注释.
+*/
+func (p Toml) put(path string, x interface{}) error {
+	var comments aString
+	var comment string
+
+	hint := InvalidKind
+	if it, ok := p.Lookup(path); ok {
+		hint = it.kind
+		if isArrayKind(hint) && hint != Array {
+			hint = hint - StringArray + String
+		}
+
+		// 同 Kind 的值直接替换
+		if it.kind < TableName {
+			if nv, err := toValue(jsonValue(x, hint)); err == nil && nv.kind == it.kind {
+				return p.Set(path, nv)
+			}
+		}
+
+		comments, comment = it.multiComments, it.eolComment
+		if err := p.Delete(path); err != nil {
+			return err
+		}
+	} else if p.exists(path) {
+		// 未声明的 TableName 整体被替换
+		if err := p.Delete(path); err != nil {
+			return err
+		}
+	}
+
+	if err := p.setJSON(path, x, hint); err != nil {
+		return err
+	}
+
+	if it, ok := p.Lookup(path); ok {
+		it.multiComments, it.eolComment = comments, comment
+	}
+	return nil
+}
+
+func (p Toml) setJSON(path string, x interface{}, hint Kind) error {
+	switch v := x.(type) {
+	case map[string]interface{}:
+		if err := p.makeTable(path); err != nil {
+			return err
+		}
+		return p.mergePatch(path, v)
+	case []map[string]interface{}:
+		return p.setTables(path, v, Toml.mergePatch)
+	case []interface{}:
+		if ms, ok := asTables(v); ok {
+			return p.setJSON(path, ms, hint)
+		}
+	}
+	return p.Set(path, jsonValue(x, hint))
+}
+
+// makeTable 确保 path 是 TableName, 缺少时建立.
+func (p Toml) makeTable(path string) error {
+	segs, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	if len(segs[len(segs)-1].idx) != 0 {
+		return NotSupported
+	}
+
+	tm, key, undo, err := p.makeParent(segs)
+	if err != nil {
+		return err
+	}
+
+	it, ok := tm[key]
+	if !ok || !it.IsValid() {
+		tm[key] = GenItem(TableName)
+		return nil
+	}
+	if it.kind != TableName {
+		undo()
+		return NotSupported
+	}
+	return nil
+}
+
+// asTables 判断 JSON 数组是否可以作为 ArrayOfTables.
+func asTables(a []interface{}) ([]map[string]interface{}, bool) {
+	if len(a) == 0 {
+		return nil, false
+	}
+	ms := make([]map[string]interface{}, len(a))
+	for i, x := range a {
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		ms[i] = m
+	}
+	return ms, true
+}
+
+/**
+jsonValue 把 JSON 的值转换为 toValue 支持的类型.
+json.Number 按照是否含有小数点或指数转换为 int64 或 float64,
+hint 为 Datetime 时字符串按 RFC3339 转换为 time.Time.
+*/
+func jsonValue(x interface{}, hint Kind) interface{} {
+	switch v := x.(type) {
+	case json.Number:
+		if strings.IndexAny(string(v), ".eE") == -1 {
+			if n, err := v.Int64(); err == nil {
+				return n
+			}
+		}
+		f, _ := v.Float64()
+		return f
+	case string:
+		if hint == Datetime {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t
+			}
+		}
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = jsonValue(e, hint)
+		}
+		return a
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = jsonValue(e, InvalidKind)
+		}
+		return m
+	}
+	return x
+}
+
+// jsonNormal 把 valueAt 和 JSON 的值转换为可比较的形式, 数值统一为 float64.
+func jsonNormal(x interface{}) interface{} {
+	switch v := x.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case int64:
+		return float64(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = jsonNormal(e)
+		}
+		return a
+	case []map[string]interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = jsonNormal(e)
+		}
+		return a
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = jsonNormal(e)
+		}
+		return m
+	}
+	return x
+}
+
+// valueAt 返回 path 对应的值, TableName 返回 map, ArrayOfTables 返回 []map.
+func (p Toml) valueAt(path string) (interface{}, error) {
+	segs, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := p.lookup(segs)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case t.elem != nil:
+		return t.elem.ToMap(), nil
+	case t.v == nil || t.v.kind == TableName:
+		// 包括未声明的 TableName
+		return t.tm.Fetch(t.key).ToMap(), nil
+	}
+	return Item{t.v}.toInterface(), nil
+}
@@ -0,0 +1,118 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	wt.Nil(ApplyPatch(tm, []byte(`[
+		{"op": "replace", "path": "/title", "value": "TOML Patch"},
+		{"op": "add", "path": "/database/ports/-", "value": 8003},
+		{"op": "add", "path": "/database/ports/0", "value": 8000},
+		{"op": "remove", "path": "/database/enabled"},
+		{"op": "replace", "path": "/products/1/name", "value": "Screw"},
+		{"op": "add", "path": "/products/-", "value": {"name": "Saw", "sku": 1}},
+		{"op": "copy", "from": "/servers/alpha", "path": "/servers/gamma"},
+		{"op": "move", "from": "/owner/dob", "path": "/owner/birthday"},
+		{"op": "add", "path": "/owner/dob", "value": "2014-01-02T03:04:05Z"},
+		{"op": "test", "path": "/servers/gamma", "value": {"ip": "10.0.0.1", "dc": "eqdc10"}},
+		{"op": "test", "path": "/fruit/0/variety/1/name", "value": "granny smith"}
+	]`)))
+
+	wt.Equal(tm["title"].String(), "TOML Patch")
+	wt.Equal(tm["database.ports"].IntArray(), []int64{8000, 8001, 8001, 8002, 8003})
+	wt.False(tm.Get("database.enabled").IsValid())
+	wt.Equal(tm.Get("products[1].name").String(), "Screw")
+	wt.Equal(tm.Get("products[1].color").String(), "gray")
+	wt.Equal(tm.Get("products[2].sku").Integer(), 1)
+	wt.Equal(tm["servers.gamma.dc"].String(), "eqdc10")
+	wt.Equal(tm["owner.birthday"].Comment(), "# First class dates? Why not?")
+	wt.Equal(tm["owner.dob"].Kind(), String)
+
+	// 未受影响的注释被保留
+	wt.Equal(tm["owner"].Comment(), "# owner information")
+	wt.Equal(tm["servers.alpha"].Comments(), []string{"# You can indent as you please. Tabs or spaces. TOML don't care."})
+
+	ntm, err := Parse([]byte(tm.String()))
+	wt.Nil(err, tm.String())
+	wt.Equal(ntm.Get("products[2].name").String(), "Saw")
+	wt.Equal(ntm.Get("servers.gamma.ip").String(), "10.0.0.1")
+}
+
+func TestApplyPatchFailed(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+	src := tm.String()
+
+	// 任何一步失败都不修改 tm
+	for err, patch := range map[error]string{
+		NotSupported:  `[{"op": "remove", "path": "/title"}, {"op": "add", "path": "/database/ports/0", "value": "8000"}]`,
+		NotFound:      `[{"op": "replace", "path": "/nothing", "value": 1}]`,
+		InvalidPath:   `[{"op": "add", "path": "/database/ports/9", "value": 1}]`,
+		TestFailed:    `[{"op": "test", "path": "/database/ports", "value": [8001, 8002]}]`,
+		InValidFormat: `[{"op": "add", "path": "/title"}]`,
+	} {
+		wt.Equal(ApplyPatch(tm, []byte(patch)), err, patch)
+		wt.Equal(tm.String(), src)
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	wt.Nil(ApplyPatch(tm, []byte(`{
+		"title": "TOML Merge",
+		"owner": {"dob": null, "name": "Tom"},
+		"servers": {"beta": null, "alpha": {"ip": "10.0.0.9"}},
+		"database": {"ports": [9001, 9002], "enabled": false},
+		"clients": "none",
+		"log": {"level": "debug", "files": [{"path": "a.log"}, {"path": "b.log"}]}
+	}`)))
+
+	wt.Equal(tm["title"].String(), "TOML Merge")
+	wt.False(tm.Get("owner.dob").IsValid())
+	wt.Equal(tm["owner.name"].String(), "Tom")
+	wt.Equal(tm["owner.organization"].String(), "GitHub")
+	wt.False(tm.Get("servers.beta").IsValid())
+	wt.False(tm.Get("servers.beta.ip").IsValid())
+	wt.Equal(tm["servers.alpha.ip"].String(), "10.0.0.9")
+	wt.Equal(tm["database.ports"].IntArray(), []int64{9001, 9002})
+	wt.False(tm["database.enabled"].Boolean())
+	wt.Equal(tm["clients"].String(), "none")
+	wt.False(tm.Get("clients.hosts").IsValid())
+	wt.Equal(tm["log"].Kind(), TableName)
+	wt.Equal(tm.Get("log.files[1].path").String(), "b.log")
+
+	ntm, err := Parse([]byte(tm.String()))
+	wt.Nil(err, tm.String())
+	wt.Equal(ntm.Get("log.level").String(), "debug")
+	wt.Equal(ntm.Get("log.files[0].path").String(), "a.log")
+}
+
+func TestApplyPatchImplicit(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte("[a.b]\nc = 1\n"))
+	wt.Nil(err)
+
+	wt.Nil(ApplyPatch(tm, []byte(`{"a": 1}`)))
+	wt.Equal(tm.Keys(), []string{"a"})
+	wt.Equal(tm.Get("a").Integer(), 1)
+
+	tm, err = Parse([]byte("[a.b]\nc = 1\n"))
+	wt.Nil(err)
+	wt.Nil(ApplyPatch(tm, []byte(`[
+		{"op": "copy", "from": "/a", "path": "/x"},
+		{"op": "replace", "path": "/a", "value": "s"}
+	]`)))
+	wt.Equal(tm.Get("a").String(), "s")
+	wt.False(tm.Get("a.b").IsValid())
+	wt.Equal(tm.Get("x.b.c").Integer(), 1)
+}
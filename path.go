@@ -0,0 +1,236 @@
+package toml
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	NotFound    = errors.New("not found")
+	InvalidPath = errors.New("invalid path")
+)
+
+// 访问路径中的一段, key 是 TableName 或 Key 的一节, idx 是跟随其后的下标.
+type pathSeg struct {
+	key string
+	idx []int
+}
+
+/**
+splitPath 解析访问路径, 路径由 "." 连接的若干段组成, 每段之后可以跟随多个 "[n]" 下标.
+含有 ".", "[", "]" 或空白的段可以用双引号包裹, 引号内的转义规则同 Go 字符串.
+例如:
+	servers.alpha.ip
+	products[1].name
+	clients.data[0][-1]
+	"a.b".c
+*/
+func splitPath(path string) (segs []pathSeg, err error) {
+	i, l := 0, len(path)
+	if l == 0 {
+		return nil, InvalidPath
+	}
+
+	for i < l {
+		var seg pathSeg
+
+		if path[i] == '"' {
+			j := i + 1
+			for ; j < l && path[j] != '"'; j++ {
+				if path[j] == '\\' {
+					j++
+				}
+			}
+			if j >= l {
+				return nil, InvalidPath
+			}
+			seg.key, err = strconv.Unquote(path[i : j+1])
+			if err != nil {
+				return nil, InvalidPath
+			}
+			i = j + 1
+		} else {
+			j := i
+			for ; j < l && path[j] != '.' && path[j] != '['; j++ {
+				if path[j] == ']' || path[j] == '"' {
+					return nil, InvalidPath
+				}
+			}
+			seg.key = strings.TrimSpace(path[i:j])
+			if seg.key == "" {
+				return nil, InvalidPath
+			}
+			i = j
+		}
+
+		for i < l && path[i] == '[' {
+			j := strings.IndexByte(path[i:], ']')
+			if j == -1 {
+				return nil, InvalidPath
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(path[i+1 : i+j]))
+			if err != nil {
+				return nil, InvalidPath
+			}
+			seg.idx = append(seg.idx, n)
+			i += j + 1
+		}
+
+		segs = append(segs, seg)
+
+		if i == l {
+			break
+		}
+		if path[i] != '.' || i == l-1 {
+			return nil, InvalidPath
+		}
+		i++
+	}
+	return
+}
+
+// target 是访问路径的解析结果.
+type target struct {
+	tm     Toml   // 目标所在的 Toml, ArrayOfTables 中的元素是独立的 Toml
+	key    string // 目标在 tm 中的 key
+	it     Item   // tm[key]
+	v      *Value // 最终的 Value, 数组元素时为该元素, ArrayOfTables 元素和未声明的 TableName 时为 nil
+	elem   Toml   // 路径以 ArrayOfTables 下标结束时, 对应的元素
+	parent *Value // 数组元素所在的数组
+	idx    int    // 最后一个下标, 已转换为非负数
+}
+
+// 规范化下标, 支持倒序下标.
+func normIndex(idx, size int) (int, bool) {
+	if idx < 0 {
+		idx = size + idx
+	}
+	return idx, idx >= 0 && idx < size
+}
+
+func (p Toml) lookup(segs []pathSeg) (t target, err error) {
+	tm, prefix := p, ""
+	max := len(segs) - 1
+
+	for i, seg := range segs {
+		key := seg.key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		it, ok := tm[key]
+		if !ok || !it.IsValid() {
+			// 未声明的上级 TableName, 比如只有 [a.b] 没有 [a]
+			if len(seg.idx) != 0 {
+				return t, NotFound
+			}
+			if i == max {
+				// 作为目标时 it 和 v 都为空
+				if !tm.hasChildren(key) {
+					return t, NotFound
+				}
+				return target{tm: tm, key: key}, nil
+			}
+			prefix = key
+			continue
+		}
+
+		if len(seg.idx) == 0 {
+			if i == max {
+				return target{tm: tm, key: key, it: it, v: it.Value}, nil
+			}
+			if it.kind != TableName {
+				return t, NotFound
+			}
+			prefix = key
+			continue
+		}
+
+		if it.kind == ArrayOfTables {
+			if len(seg.idx) != 1 {
+				return t, InvalidPath
+			}
+			n, ok := normIndex(seg.idx[0], it.Len())
+			if !ok {
+				return t, NotFound
+			}
+			elem := it.Table(n)
+			if i == max {
+				return target{tm: tm, key: key, it: it, elem: elem, idx: n}, nil
+			}
+			tm, prefix = elem, ""
+			continue
+		}
+
+		// Array 及 typeArray, 数组元素之下没有其他元素了
+		if i != max {
+			return t, NotFound
+		}
+
+		t = target{tm: tm, key: key, it: it, v: it.Value}
+		for _, n := range seg.idx {
+			t.parent = t.v
+			t.idx, ok = normIndex(n, t.v.Len())
+			if !ok {
+				return target{}, NotFound
+			}
+			t.v = t.v.Index(t.idx)
+		}
+		return
+	}
+	return t, NotFound
+}
+
+/**
+Lookup 根据访问路径返回对应的 Item, 路径不存在时 ok 为 false.
+与 map 的 key 不同, 访问路径支持下标, 可以深入 ArrayOfTables 和数组内部:
+	tm.Lookup("servers.alpha.ip")
+	tm.Lookup("products[1].name")   // ArrayOfTables 元素中的 Key
+	tm.Lookup("fruit[0].variety[-1].name")
+	tm.Lookup("clients.data[0][1]") // 数组元素
+	tm.Lookup(`"a.b".c`)            // 引号包裹的段
+下标可以是负数, 表示倒序.
+路径以 ArrayOfTables 下标结束时不返回 Item, 请使用 LookupTable.
+*/
+func (p Toml) Lookup(path string) (it Item, ok bool) {
+	segs, err := splitPath(path)
+	if err != nil {
+		return
+	}
+	t, err := p.lookup(segs)
+	if err != nil || t.v == nil {
+		return
+	}
+	return Item{t.v}, true
+}
+
+// Get 是 Lookup 的便捷方法, 路径不存在时返回的 Item 无效, 可以用 IsValid() 判断.
+func (p Toml) Get(path string) Item {
+	it, _ := p.Lookup(path)
+	return it
+}
+
+/**
+LookupTable 返回访问路径对应的 Table.
+路径为 TableName 时, 返回值同 Fetch, 路径以 ArrayOfTables 下标结束时, 返回该元素.
+	tm.LookupTable("servers.alpha")
+	tm.LookupTable("products[-1]")
+*/
+func (p Toml) LookupTable(path string) (tm Toml, ok bool) {
+	segs, err := splitPath(path)
+	if err != nil {
+		return
+	}
+	t, err := p.lookup(segs)
+	if err != nil {
+		return
+	}
+	if t.elem != nil {
+		return t.elem, true
+	}
+	if t.v == nil || t.v.kind != TableName {
+		return
+	}
+	return t.tm.Fetch(t.key), true
+}
@@ -0,0 +1,87 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+)
+
+func TestSplitPath(t *testing.T) {
+	wt := want.T(t)
+
+	segs, err := splitPath(`servers.alpha.ip`)
+	wt.Nil(err)
+	wt.Equal(segs, []pathSeg{{"servers", nil}, {"alpha", nil}, {"ip", nil}})
+
+	segs, err = splitPath(`fruit[0].variety[-1].name`)
+	wt.Nil(err)
+	wt.Equal(segs, []pathSeg{{"fruit", []int{0}}, {"variety", []int{-1}}, {"name", nil}})
+
+	segs, err = splitPath(`clients.data[0][1]`)
+	wt.Nil(err)
+	wt.Equal(segs, []pathSeg{{"clients", nil}, {"data", []int{0, 1}}})
+
+	segs, err = splitPath(`"a.b"."[c]"[2]`)
+	wt.Nil(err)
+	wt.Equal(segs, []pathSeg{{"a.b", nil}, {"[c]", []int{2}}})
+
+	for _, path := range []string{``, `.`, `a.`, `.a`, `a..b`, `a[`, `a[x]`, `a]`, `"a`, `"a"b`} {
+		_, err = splitPath(path)
+		wt.Equal(err, InvalidPath, path)
+	}
+}
+
+func TestTomlLookup(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	wt.Equal(tm.Get("servers.alpha.ip").String(), "10.0.0.1")
+	wt.Equal(tm.Get("servers").Kind(), TableName)
+	wt.Equal(tm.Get("products").Kind(), ArrayOfTables)
+	wt.Equal(tm.Get("products[1].name").String(), "Nail")
+	wt.Equal(tm.Get("products[-2].sku").Integer(), 738594937)
+	wt.Equal(tm.Get("fruit[0].physical.color").String(), "red")
+	wt.Equal(tm.Get("fruit[0].variety[1].name").String(), "granny smith")
+	wt.Equal(tm.Get("fruit[-1].variety[0].name").String(), "plantain")
+	wt.Equal(tm.Get("database.ports[2]").Integer(), 8002)
+	wt.Equal(tm.Get("database.ports[-3]").Integer(), 8001)
+	wt.Equal(tm.Get("clients.data[0][1]").String(), "delta")
+	wt.Equal(tm.Get("clients.data[1][-1]").Integer(), 2)
+
+	for _, path := range []string{
+		"nothing",
+		"servers.gamma.ip",
+		"products.name",     // ArrayOfTables 需要下标
+		"products[2].name",  // 超出下标
+		"database.ports[3]", // 超出下标
+		"database.ports.x",
+		"title[0]",
+		"products[0]", // 需要 LookupTable
+	} {
+		_, ok := tm.Lookup(path)
+		wt.False(ok, path)
+		wt.False(tm.Get(path).IsValid(), path)
+	}
+
+	sub, ok := tm.LookupTable("servers.alpha")
+	wt.True(ok)
+	wt.Equal(sub["dc"].String(), "eqdc10")
+
+	sub, ok = tm.LookupTable("fruit[1]")
+	wt.True(ok)
+	wt.Equal(sub["name"].String(), "banana")
+
+	_, ok = tm.LookupTable("title")
+	wt.False(ok)
+}
+
+func TestTomlLookupImplicitTable(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte(`
+[a.b]
+c = 1
+`))
+	wt.Nil(err)
+	wt.Equal(tm.Get("a.b.c").Integer(), 1)
+	wt.False(tm.Get("a").IsValid())
+}
@@ -0,0 +1,91 @@
+package toml
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Span 是 source 中 [Start, End) 的范围.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// IsValid 返回 Span 是否有效, 非解析得到的 Value 没有位置.
+func (s Span) IsValid() bool {
+	return s.Start.Line > 0
+}
+
+/**
+Location 是解析得到的 Value 在 source 中的位置.
+Key 是 Key, [TableName] 或者 [[ArrayOfTables]] 的位置, 数组元素没有 Key.
+Value 是值的位置, 数组包括 "[" 和 "]", TableName 和 ArrayOfTables 没有值.
+ArrayOfTables 的 Key 是第一个 [[...]] 的位置, 每个 Table 的 Id 保存各自的 [[...]] 的位置.
+*/
+type Location struct {
+	File  string // 由 LoadFile 解析时为文件名
+	Key   Span
+	Value Span
+}
+
+// String 返回 "file:line:column" 格式的位置, 优先使用值的位置.
+func (l Location) String() string {
+	s := l.Value
+	if !s.IsValid() {
+		s = l.Key
+	}
+	if !s.IsValid() {
+		return ""
+	}
+	if l.File == "" {
+		return s.Start.String()
+	}
+	return l.File + ":" + s.Start.String()
+}
+
+/**
+Position 返回 Value 在 source 中的位置. 由 Set, Add 等方法建立的 Value 返回零值.
+Clone 的副本保留原来的位置, 修改值不会改变位置.
+*/
+func (p *Value) Position() Location {
+	if p == nil || p.loc == nil {
+		return Location{}
+	}
+	return *p.loc
+}
+
+// tracker 跟踪连续的 Token 的位置.
+type tracker struct {
+	pos  Position
+	last rune
+}
+
+func newTracker(source []byte) tracker {
+	t := tracker{pos: Position{Line: 1, Column: 1}}
+	if bytes.HasPrefix(source, []byte("\xEF\xBB\xBF")) {
+		t.pos.Offset = 3
+	}
+	return t
+}
+
+// advance 越过 s 并返回 s 的范围.
+func (t *tracker) advance(s string) Span {
+	start := t.pos
+	t.pos, t.last = t.pos.advance(s, t.last)
+	return Span{start, t.pos}
+}
+
+// trimmed 越过 raw 并返回其中 str 的范围, str 是 raw 去掉首尾空白的部分.
+func (t *tracker) trimmed(raw, str string) Span {
+	if len(raw) == len(str) {
+		return t.advance(raw)
+	}
+	i := strings.Index(raw, str)
+	if i == -1 {
+		return t.advance(raw)
+	}
+	t.advance(raw[:i])
+	span := t.advance(str)
+	t.advance(raw[i+len(str):])
+	return span
+}
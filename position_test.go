@@ -0,0 +1,56 @@
+package toml
+
+import (
+	"github.com/achun/testing-want"
+	"testing"
+)
+
+func TestValuePosition(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	loc := tm["title"].Position()
+	wt.Equal(loc.File, "tests/example.toml")
+	wt.Equal(loc.Key, Span{Position{34, 3, 1}, Position{39, 3, 6}})
+	wt.Equal(loc.Value, Span{Position{42, 3, 9}, Position{56, 3, 23}})
+	wt.Equal(loc.String(), "tests/example.toml:3:9")
+
+	loc = tm["owner"].Position()
+	wt.Equal(loc.Key.Start.Line, 5)
+	wt.Equal(loc.Key.End.Column, 8)
+	wt.False(loc.Value.IsValid())
+	wt.Equal(loc.String(), "tests/example.toml:5:1")
+
+	loc = tm["database.ports"].Position()
+	wt.Equal(loc.Key.Start.String(), "13:1")
+	wt.Equal(loc.Value.Start.String(), "13:9")
+	wt.Equal(loc.Value.End.String(), "13:29")
+	wt.Equal(tm.Get("database.ports[1]").Position().String(), "tests/example.toml:13:17")
+	wt.False(tm.Get("database.ports[1]").Position().Key.IsValid())
+
+	loc = tm["clients.data"].Position()
+	wt.Equal(loc.Value.Start.String(), "30:8")
+	wt.Equal(loc.Value.End.String(), "30:38")
+	loc = tm.Get("clients.data[1]").Position()
+	wt.Equal(loc.Value.Start.String(), "30:30")
+	wt.Equal(loc.Value.End.String(), "30:36")
+	wt.Equal(tm.Get("clients.data[1][0]").Position().String(), "tests/example.toml:30:31")
+	wt.Equal(tm["clients.hosts"].Position().Value.End.String(), "36:2")
+
+	wt.Equal(tm["products"].Position().Key.Start.String(), "40:3")
+	id := tm["products"].Table(1).Id()
+	wt.Equal(id.Position().Key.Start.String(), "44:3")
+	wt.Equal(tm.Get("products[1].color").Position().String(), "tests/example.toml:47:11")
+	wt.Equal(tm.Get("fruit[1].variety[0].name").Position().Key.Start.String(), "67:5")
+
+	// 非解析得到的 Value 没有位置, 副本保留位置
+	wt.Nil(tm.Set("owner.age", 40))
+	wt.Equal(tm["owner.age"].Position(), Location{})
+	wt.Equal(tm["title"].Clone().Position(), tm["title"].Position())
+
+	tm, err = Parse([]byte("\xEF\xBB\xBFa = 1\r\nb = \"x\" # c\n"))
+	wt.Nil(err)
+	wt.Equal(tm["a"].Position().Key.Start, Position{3, 1, 1})
+	wt.Equal(tm["b"].Position().String(), "2:5")
+}
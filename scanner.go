@@ -19,6 +19,7 @@ type Scanner interface {
 
 type scanner struct {
 	buf    []byte
+	src    string // buf 的副本, Fetch 返回其子串而不必每次复制
 	pos    int
 	offset int // for Get()
 	size   int
@@ -32,6 +33,7 @@ type scanner struct {
 func NewScanner(source []byte) Scanner {
 	p := scanner{}
 	p.buf = source
+	p.src = string(source)
 	r := p.Next()
 
 	if r == BOM {
@@ -65,7 +67,7 @@ func (p *scanner) Fetch(last bool) (str string) {
 		e -= p.size
 	}
 
-	str = string(p.buf[p.offset:e])
+	str = p.src[p.offset:e]
 	p.offset = e
 	return
 }
@@ -0,0 +1,232 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/achun/tom-toml"
+)
+
+/**
+Generate 由 Go struct 生成带注释的 TOML 示例文档以及对应的 Schema. x 是 struct 或者其指针.
+
+字段使用以下 tag:
+	toml:"name"           元素的名称, 省略时使用字段名, "-" 表示忽略该字段.
+	toml:"name,required"  Schema 中该元素是必须的.
+	comment:"..."         元素的注释, 可以有多行, 每行一个注释.
+	default:"..."         示例文档中的值, String 直接使用, 其他类型按 TOML 的值解析,
+	                      比如 default:"[8001, 8002]". 省略时使用 x 中字段的值.
+
+Go 类型与 Kind 的对应:
+	string                String
+	bool                  Boolean
+	int*, uint*           Integer
+	float*                Float
+	time.Time             Datetime
+	struct                TableName, 其 Schema 不允许未声明的元素
+	[]struct              ArrayOfTables, 示例文档中至少有一个 Table
+	其他 slice, array      对应的数组, 比如 []string 为 StringArray, [][]int 为 Array
+指针按其指向的类型处理. 不支持 map, interface 等其他类型.
+
+	type Config struct {
+		Title    string `toml:"title" comment:"This is a TOML document." default:"TOML Example"`
+		Database struct {
+			Ports []int `toml:"ports,required" default:"[8001, 8002]"`
+		} `toml:"database"`
+	}
+	tm, s, err := schema.Generate(Config{})
+	fmt.Println(tm.String())
+*/
+func Generate(x interface{}) (toml.Toml, *Schema, error) {
+	rv := reflect.Indirect(reflect.ValueOf(x))
+	if rv.Kind() != reflect.Struct || rv.Type() == timeType {
+		return nil, nil, fmt.Errorf("schema: Generate requires a struct, %v", toml.NotSupported)
+	}
+
+	tm := toml.New()
+	s := &Schema{Kind: toml.TableName}
+	if err := generate(tm, "", rv, s); err != nil {
+		return nil, nil, err
+	}
+	return tm, s, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// elem 返回 t 去掉指针后的类型.
+func elem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func isStruct(t reflect.Type) bool {
+	t = elem(t)
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// generate 把 struct rv 的字段写入 tm 中 prefix 之下, 并建立 s.Keys.
+func generate(tm toml.Toml, prefix string, rv reflect.Value, s *Schema) error {
+	rt := rv.Type()
+	s.Strict = true
+	s.Keys = map[string]*Schema{}
+
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, opts := f.Name, ""
+		if tag, ok := f.Tag.Lookup("toml"); ok {
+			if tag == "-" {
+				continue
+			}
+			if pos := strings.IndexByte(tag, ','); pos != -1 {
+				tag, opts = tag[:pos], tag[pos:]
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		if strings.IndexAny(name, " \t\r\n=#.[]\"") != -1 {
+			return fmt.Errorf("schema: field %s: invalid key %q", f.Name, name)
+		}
+
+		key := prefix + name
+		fs := &Schema{Required: strings.Contains(opts, ",required")}
+		s.Keys[name] = fs
+		s.order = append(s.order, name)
+
+		var comments []string
+		if c := f.Tag.Get("comment"); c != "" {
+			comments = strings.Split(c, "\n")
+		}
+
+		if err := field(tm, key, f, rv.Field(i), fs, comments); err != nil {
+			return fmt.Errorf("schema: field %s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func field(tm toml.Toml, key string, f reflect.StructField, fv reflect.Value, s *Schema, comments []string) error {
+	ft := elem(f.Type)
+	fv = reflect.Indirect(fv)
+	if !fv.IsValid() {
+		fv = reflect.New(ft).Elem()
+	}
+
+	switch {
+	case isStruct(ft):
+		s.Kind = toml.TableName
+		it := toml.GenItem(toml.TableName)
+		it.SetComments(comments)
+		tm[key] = it
+		return generate(tm, key+".", fv, s)
+
+	case (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array) && isStruct(ft.Elem()):
+		s.Kind = toml.ArrayOfTables
+		it := toml.GenItem(toml.ArrayOfTables)
+		tm[key] = it
+
+		n := fv.Len()
+		for i := 0; i == 0 || i < n; i++ {
+			ev := reflect.New(elem(ft.Elem())).Elem()
+			if i < n {
+				if v := reflect.Indirect(fv.Index(i)); v.IsValid() {
+					ev = v
+				}
+			}
+			// 每个 Table 的 Schema 都相同, 只保留第一个. 注释输出在第一个 [[...]] 之前
+			es := s
+			et := toml.New()
+			if i == 0 {
+				et.SetComments(comments)
+			} else {
+				es = &Schema{}
+			}
+			if err := generate(et, "", ev, es); err != nil {
+				return err
+			}
+			if err := it.AddTable(et); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	kind, err := kindOfType(ft)
+	if err != nil {
+		return err
+	}
+	s.Kind = kind
+
+	var v *toml.Value
+	if d, ok := f.Tag.Lookup("default"); ok {
+		v, err = defaultValue(d, kind)
+	} else {
+		v, err = toml.ValueOf(fv.Interface())
+	}
+	if err != nil {
+		return err
+	}
+
+	it := toml.Item{Value: v}
+	it.SetComments(comments)
+	tm[key] = it
+	return nil
+}
+
+// kindOfType 返回值类型 t 对应的 Kind.
+func kindOfType(t reflect.Type) (toml.Kind, error) {
+	t = elem(t)
+	if t == timeType {
+		return toml.Datetime, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return toml.String, nil
+	case reflect.Bool:
+		return toml.Boolean, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return toml.Integer, nil
+	case reflect.Float32, reflect.Float64:
+		return toml.Float, nil
+	case reflect.Slice, reflect.Array:
+		k, err := kindOfType(t.Elem())
+		if err != nil {
+			return k, err
+		}
+		if ak, ok := arrayKinds[k]; ok && k != toml.TableName {
+			return ak, nil
+		}
+		return toml.Array, nil
+	}
+	return toml.InvalidKind, fmt.Errorf("%s is %v", t, toml.NotSupported)
+}
+
+// defaultValue 解析 default tag, kind 为 String 时直接使用 d.
+func defaultValue(d string, kind toml.Kind) (*toml.Value, error) {
+	if kind == toml.String {
+		return toml.ValueOf(d)
+	}
+
+	tm, err := toml.Parse([]byte("v = " + d))
+	if err != nil {
+		return nil, fmt.Errorf("invalid default %q: %v", d, err)
+	}
+	v := tm["v"].Value
+	if v.Kind() == toml.Array && v.Len() == 0 && isArrayKind(kind) {
+		return toml.ValueOf([]interface{}{})
+	}
+	if v.Kind() != kind {
+		return nil, fmt.Errorf("default %q is %s, want %s", d, v.Kind(), kind)
+	}
+	return toml.ValueOf(v)
+}
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"github.com/achun/testing-want"
+	"github.com/achun/tom-toml"
+	"testing"
+	"time"
+)
+
+type product struct {
+	Name string `toml:"name,required" comment:"product name"`
+	Sku  int    `toml:"sku"`
+}
+
+type config struct {
+	Title string `toml:"title,required" comment:"This is a TOML document.\nGenerated from a Go struct." default:"TOML Example"`
+	Owner struct {
+		Name string    `toml:"name" default:"Tom"`
+		Dob  time.Time `toml:"dob" comment:"First class dates"`
+	} `toml:"owner" comment:"owner information"`
+	Database *struct {
+		Ports   []int   `toml:"ports" default:"[8001, 8002]"`
+		Enabled bool    `toml:"enabled" default:"true"`
+		Ratio   float64 `toml:"ratio" default:"0.5"`
+		Hosts   []string
+		Data    [][]int `toml:"data"`
+	} `toml:"database"`
+	Products []product `toml:"products" comment:"Products"`
+	Ignored  string    `toml:"-"`
+	private  int
+}
+
+func TestGenerate(t *testing.T) {
+	wt := want.T(t)
+	c := config{Products: []product{{"Hammer", 738594937}, {"Nail", 284758393}}}
+	c.Owner.Dob = time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)
+
+	tm, s, err := Generate(&c)
+	wt.Nil(err)
+
+	wt.Equal(tm.String(), `# This is a TOML document.
+# Generated from a Go struct.
+title = "TOML Example"
+
+# owner information
+[owner]
+
+	# First class dates
+	dob = 1979-05-27T07:32:00Z
+	name = "Tom"
+
+[database]
+	Hosts = []
+	data = []
+	enabled = true
+	ports = [8001, 8002]
+	ratio = 0.5
+
+# Products
+[[products]]
+	# product name
+	name = "Hammer"
+	sku = 738594937
+[[products]]
+	# product name
+	name = "Nail"
+	sku = 284758393
+`)
+	// 示例文档可以被再次解析, 并且符合 Schema
+	ptm, err := toml.Parse([]byte(tm.String()))
+	wt.Nil(err)
+	wt.Nil(Validate(ptm, s))
+
+	wt.Equal(s.names(), []string{"title", "owner", "database", "products"})
+	wt.True(s.Strict)
+	wt.True(s.Keys["title"].Required)
+	wt.Equal(s.Keys["owner"].Keys["dob"].Kind, toml.Datetime)
+	db := s.Keys["database"]
+	wt.Equal(db.Kind, toml.TableName)
+	wt.Equal(db.names(), []string{"ports", "enabled", "ratio", "Hosts", "data"})
+	wt.Equal(db.Keys["ports"].Kind, toml.IntegerArray)
+	wt.Equal(db.Keys["Hosts"].Kind, toml.StringArray)
+	wt.Equal(db.Keys["data"].Kind, toml.Array)
+	wt.Equal(s.Keys["products"].Kind, toml.ArrayOfTables)
+	wt.Equal(s.Keys["products"].names(), []string{"name", "sku"})
+	wt.True(s.Keys["products"].Keys["name"].Required)
+
+	ptm.Set("owner.age", 40)
+	ptm.Delete("title")
+	var got []string
+	for _, v := range Validate(ptm, s) {
+		got = append(got, v.String())
+	}
+	wt.Equal(got, []string{"title: required", "owner.age: not declared in schema"})
+
+	// 没有元素时也生成一个 Table
+	tm, _, err = Generate(config{})
+	wt.Nil(err)
+	wt.Equal(tm["products"].Len(), 1)
+
+	_, _, err = Generate(1)
+	wt.NotNil(err)
+	_, _, err = Generate(struct{ M map[string]int }{})
+	wt.NotNil(err)
+	_, _, err = Generate(struct {
+		N int `default:"x"`
+	}{})
+	wt.NotNil(err)
+	_, _, err = Generate(struct {
+		N int `default:"1.5"`
+	}{})
+	wt.NotNil(err)
+}
@@ -0,0 +1,278 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/achun/tom-toml"
+)
+
+/**
+FromJSONSchema 由 JSON Schema (draft 2020-12) 建立 Schema, 支持以下关键字:
+	type, properties, required, items, enum, minimum, maximum, pattern,
+	additionalProperties, minItems, maxItems, format
+其他关键字被忽略. additionalProperties 只支持 true 和 false, enum 只支持字符串, 数字和布尔值.
+type 与 items 矛盾时返回错误, 比如 "type": "string" 与 "items": {...}.
+
+JSON 类型与 Kind 的对应:
+	object                     TableName
+	string                     String, "format": "date-time" 时为 Datetime
+	integer                    Integer
+	number                     Integer 或 Float
+	boolean                    Boolean
+	array                      items 为 object 时是 ArrayOfTables, 否则是对应的数组,
+	                           比如 items 为 string 时是 StringArray, 没有 items 时可以是任何数组
+	null                       忽略, TOML 没有 null
+items 中的 enum, minimum, maximum, pattern 用于数组的每一个元素.
+*/
+func FromJSONSchema(data []byte) (*Schema, error) {
+	var x interface{}
+	if err := json.Unmarshal(data, &x); err != nil {
+		return nil, err
+	}
+	m, ok := x.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema: JSON Schema must be an object")
+	}
+
+	s, err := fromJSON(m, "#")
+	if err != nil {
+		return nil, err
+	}
+	if !s.allow(toml.TableName) || len(s.Kinds) > 1 {
+		return nil, fmt.Errorf("schema: type of the root must be object")
+	}
+	s.Kind, s.Kinds = toml.TableName, nil
+	return s, nil
+}
+
+// LoadJSONSchema 读取 JSON Schema 文件, 参见 FromJSONSchema.
+func LoadJSONSchema(path string) (*Schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSONSchema(data)
+}
+
+var (
+	jsonKinds = map[string][]toml.Kind{
+		"object":  {toml.TableName},
+		"string":  {toml.String},
+		"integer": {toml.Integer},
+		"number":  {toml.Integer, toml.Float},
+		"boolean": {toml.Boolean},
+		"array":   {toml.StringArray, toml.IntegerArray, toml.FloatArray, toml.BooleanArray, toml.DatetimeArray, toml.Array, toml.ArrayOfTables},
+		"null":    nil,
+	}
+
+	// 元素的 Kind 对应的数组的 Kind
+	arrayKinds = map[toml.Kind]toml.Kind{
+		toml.TableName: toml.ArrayOfTables,
+		toml.String:    toml.StringArray,
+		toml.Integer:   toml.IntegerArray,
+		toml.Float:     toml.FloatArray,
+		toml.Boolean:   toml.BooleanArray,
+		toml.Datetime:  toml.DatetimeArray,
+	}
+)
+
+// fromJSON 由 JSON Schema 对象建立 Schema, at 是 JSON Pointer 形式的位置, 用于错误信息.
+func fromJSON(m map[string]interface{}, at string) (s *Schema, err error) {
+	s = &Schema{}
+	errorf := func(key, format string, a ...interface{}) error {
+		return fmt.Errorf("schema: %s/%s: %s", at, key, fmt.Sprintf(format, a...))
+	}
+
+	var kinds []toml.Kind
+	switch t := m["type"].(type) {
+	case nil:
+		if _, ok := m["properties"]; ok {
+			kinds = jsonKinds["object"]
+		}
+	case string:
+		if kinds, err = jsonType(t); err != nil {
+			return nil, errorf("type", "%v", err)
+		}
+	case []interface{}:
+		for _, e := range t {
+			name, _ := e.(string)
+			ks, err := jsonType(name)
+			if err != nil {
+				return nil, errorf("type", "%v", err)
+			}
+			kinds = append(kinds, ks...)
+		}
+	default:
+		return nil, errorf("type", "must be a string or an array")
+	}
+
+	if m["format"] == "date-time" {
+		for i, k := range kinds {
+			if k == toml.String {
+				kinds[i] = toml.Datetime
+			}
+		}
+	}
+
+	if err = s.constraints(m, errorf); err != nil {
+		return nil, err
+	}
+
+	if items, ok := m["items"].(map[string]interface{}); ok {
+		is, err := fromJSON(items, at+"/items")
+		if err != nil {
+			return nil, err
+		}
+		// 元素的约束用于数组的每一个元素, object 的 Keys 用于 ArrayOfTables
+		s.Min, s.Max, s.Pattern, s.Enum = is.Min, is.Max, is.Pattern, is.Enum
+		s.Keys, s.Strict = is.Keys, is.Strict
+
+		ik := is.Kinds
+		if len(ik) == 0 && is.Kind != toml.InvalidKind {
+			ik = []toml.Kind{is.Kind}
+		}
+		if len(ik) != 0 {
+			var array []toml.Kind
+			for _, k := range ik {
+				if ak, ok := arrayKinds[k]; ok {
+					array = append(array, ak)
+				} else if isArrayKind(k) {
+					array = append(array, toml.Array)
+				}
+			}
+			if kinds = intersect(kinds, array); len(kinds) == 0 {
+				return nil, errorf("items", "no array kind matches type and items")
+			}
+		}
+	}
+
+	if props, ok := m["properties"].(map[string]interface{}); ok {
+		s.Keys = map[string]*Schema{}
+		for name, p := range props {
+			pm, ok := p.(map[string]interface{})
+			if !ok {
+				return nil, errorf("properties/"+name, "must be an object")
+			}
+			if s.Keys[name], err = fromJSON(pm, at+"/properties/"+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if required, ok := m["required"].([]interface{}); ok {
+		if s.Keys == nil {
+			s.Keys = map[string]*Schema{}
+		}
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				return nil, errorf("required", "must be an array of strings")
+			}
+			if s.Keys[name] == nil {
+				s.Keys[name] = &Schema{}
+			}
+			s.Keys[name].Required = true
+		}
+	}
+
+	switch ap := m["additionalProperties"].(type) {
+	case nil:
+	case bool:
+		s.Strict = !ap
+	default:
+		return nil, errorf("additionalProperties", "only true or false is supported")
+	}
+
+	if len(kinds) == 1 {
+		s.Kind = kinds[0]
+	} else {
+		s.Kinds = kinds
+	}
+	return s, nil
+}
+
+func jsonType(name string) ([]toml.Kind, error) {
+	kinds, ok := jsonKinds[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", name)
+	}
+	return append([]toml.Kind{}, kinds...), nil
+}
+
+// intersect 返回 a 中也在 b 中的 Kind, a 为空表示任意 Kind.
+func intersect(a, b []toml.Kind) []toml.Kind {
+	if len(a) == 0 {
+		return b
+	}
+	var c []toml.Kind
+	for _, k := range a {
+		for _, e := range b {
+			if k == e {
+				c = append(c, k)
+				break
+			}
+		}
+	}
+	return c
+}
+
+// constraints 读取 enum, minimum, maximum, pattern, minItems, maxItems.
+func (s *Schema) constraints(m map[string]interface{}, errorf func(key, format string, a ...interface{}) error) error {
+	for _, key := range []string{"minimum", "maximum", "minItems", "maxItems"} {
+		x, ok := m[key]
+		if !ok {
+			continue
+		}
+		f, ok := x.(float64)
+		if !ok {
+			return errorf(key, "must be a number")
+		}
+		switch key {
+		case "minimum":
+			s.Min = &f
+		case "maximum":
+			s.Max = &f
+		case "minItems":
+			s.MinLen = int(f)
+		case "maxItems":
+			s.MaxLen = int(f)
+		}
+	}
+
+	if x, ok := m["pattern"]; ok {
+		p, ok := x.(string)
+		if !ok {
+			return errorf("pattern", "must be a string")
+		}
+		var err error
+		if s.Pattern, err = regexp.Compile(p); err != nil {
+			return errorf("pattern", "%v", err)
+		}
+	}
+
+	if x, ok := m["enum"]; ok {
+		a, ok := x.([]interface{})
+		if !ok {
+			return errorf("enum", "must be an array")
+		}
+		for _, e := range a {
+			switch e := e.(type) {
+			case string, bool:
+				s.Enum = append(s.Enum, e)
+			case float64:
+				// 整数保存为 int64, 与 TOML 的 Integer 一致
+				if i := int64(e); float64(i) == e {
+					s.Enum = append(s.Enum, i)
+				} else {
+					s.Enum = append(s.Enum, e)
+				}
+			default:
+				return errorf("enum", "only strings, numbers and booleans are supported")
+			}
+		}
+	}
+	return nil
+}
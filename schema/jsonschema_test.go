@@ -0,0 +1,140 @@
+package schema
+
+import (
+	"github.com/achun/testing-want"
+	"github.com/achun/tom-toml"
+	"testing"
+)
+
+const exampleJSONSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["title", "owner", "database"],
+	"properties": {
+		"title": {"type": "string", "description": "ignored"},
+		"owner": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "pattern": "^[A-Z]"},
+				"dob": {"type": "string", "format": "date-time"}
+			}
+		},
+		"database": {
+			"type": "object",
+			"required": ["server", "mode"],
+			"additionalProperties": false,
+			"properties": {
+				"server": {"type": "string"},
+				"ports": {
+					"type": "array",
+					"items": {"type": "integer", "minimum": 1024, "maximum": 8001},
+					"maxItems": 3
+				},
+				"connection_max": {"type": "number", "maximum": 1000},
+				"enabled": {"type": "boolean"}
+			}
+		},
+		"clients": {
+			"properties": {
+				"hosts": {"type": "array", "items": {"type": "string", "enum": ["alpha", "beta"]}},
+				"data": {"type": "array", "items": {"type": "array"}}
+			}
+		},
+		"products": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"required": ["name", "price"],
+				"properties": {"sku": {"type": ["number", "null"]}}
+			}
+		},
+		"fruit": {"type": "array", "items": {"type": "string"}}
+	}
+}`
+
+func TestFromJSONSchema(t *testing.T) {
+	wt := want.T(t)
+	s, err := FromJSONSchema([]byte(exampleJSONSchema))
+	wt.Nil(err)
+
+	wt.Equal(s.Kind, toml.TableName)
+	wt.Equal(s.names(), []string{"clients", "database", "fruit", "owner", "products", "title"})
+	wt.True(s.Keys["title"].Required)
+	wt.Equal(s.Keys["owner"].Keys["dob"].Kind, toml.Datetime)
+	wt.True(s.Keys["database"].Strict)
+
+	ports := s.Keys["database"].Keys["ports"]
+	wt.Equal(ports.Kind, toml.IntegerArray)
+	wt.Equal(*ports.Min, 1024.0)
+	wt.Equal(ports.MaxLen, 3)
+
+	wt.Equal(s.Keys["database"].Keys["connection_max"].Kinds, []toml.Kind{toml.Integer, toml.Float})
+	wt.Equal(s.Keys["clients"].Kind, toml.TableName)
+	wt.Equal(s.Keys["clients"].Keys["data"].Kind, toml.Array)
+	wt.Equal(s.Keys["products"].Kind, toml.ArrayOfTables)
+	wt.True(s.Keys["products"].Keys["price"].Required)
+	wt.Equal(s.Keys["products"].Keys["sku"].Kinds, []toml.Kind{toml.Integer, toml.Float})
+
+	tm, err := toml.LoadFile("../tests/example.toml")
+	wt.Nil(err)
+
+	var got []string
+	for _, v := range Validate(tm, s) {
+		got = append(got, v.String())
+	}
+	wt.Equal(got, []string{
+		"../tests/example.toml:35:3: clients.hosts[1]: \"omega\" not in [\"alpha\" \"beta\"]",
+		"../tests/example.toml:14:18: database.connection_max: 5000 greater than 1000",
+		"../tests/example.toml:11:1: database.mode: required",
+		"../tests/example.toml:13:23: database.ports[2]: 8002 greater than 8001",
+		"../tests/example.toml:50:1: fruit: want StringArray, got ArrayOfTables",
+		"../tests/example.toml:40:3: products[0].price: required",
+		"../tests/example.toml:44:3: products[1].price: required",
+	})
+
+	for _, src := range []string{
+		`[]`,
+		`{"type": "string"}`,
+		`{"type": "date"}`,
+		`{"properties": {"a": 1}}`,
+		`{"properties": {"a": {"enum": [null]}}}`,
+		`{"properties": {"a": {"enum": [[1]]}}}`,
+		`{"properties": {"a": {"type": "string", "items": {"type": "string"}}}}`,
+		`{"properties": {"a": {"type": "array", "items": {"type": "array", "items": {"type": "object"}}}}}`,
+		`{"properties": {"a": {"pattern": "("}}}`,
+		`{"properties": {"a": {"additionalProperties": {}}}}`,
+		`{"properties": {"a": {"minimum": "1"}}}`,
+	} {
+		_, err = FromJSONSchema([]byte(src))
+		wt.NotNil(err, src)
+	}
+
+	_, err = FromJSONSchema([]byte(`{"properties": {"a": {"type": "string", "items": {"type": "integer"}}}}`))
+	wt.Equal(err.Error(), "schema: #/properties/a/items: no array kind matches type and items")
+}
+
+func TestJSONSchemaEnum(t *testing.T) {
+	wt := want.T(t)
+	s, err := FromJSONSchema([]byte(`{"properties": {
+		"level": {"type": "integer", "enum": [1, 2, 3]},
+		"ratio": {"enum": [0.5, 1]},
+		"debug": {"enum": [false]},
+		"ports": {"type": "array", "items": {"enum": [80, 443]}}
+	}}`))
+	wt.Nil(err)
+	wt.Equal(s.Keys["level"].Enum, []interface{}{int64(1), int64(2), int64(3)})
+	wt.Equal(s.Keys["ratio"].Enum, []interface{}{0.5, int64(1)})
+	wt.Equal(s.Keys["debug"].Enum, []interface{}{false})
+
+	tm, err := toml.Parse([]byte("level = 4\nratio = 1.0\ndebug = true\nports = [80, 8080]\n"))
+	wt.Nil(err)
+	var got []string
+	for _, v := range Validate(tm, s) {
+		got = append(got, v.String())
+	}
+	wt.Equal(got, []string{
+		"3:9: debug: true not in [false]",
+		"1:9: level: 4 not in [1 2 3]",
+		"4:14: ports[1]: 8080 not in [80 443]",
+	})
+}
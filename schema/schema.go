@@ -0,0 +1,293 @@
+/**
+Package schema 定义 Toml 文档的结构并进行校验.
+
+Schema 本身可以用 TOML 表达, 每个 Table 描述一个元素的规则, 下级元素的规则位于 keys 之下:
+
+	strict = true                # 不允许 keys 中没有声明的元素
+
+	[keys.title]
+	kind = "String"
+	required = true
+
+	[keys.database]
+	kind = "TableName"
+
+	[keys.database.keys.port]
+	kind = "Integer"
+	min = 1
+	max = 65535
+
+	[keys.database.keys.mode]
+	kind = "String"
+	enum = ["rw", "ro"]
+
+	[keys.products]
+	kind = "ArrayOfTables"
+	minLen = 1
+
+	[keys.products.keys.sku]
+	kind = "Integer"
+	required = true
+
+规则:
+	kind      Kind 的名称, 比如 "String", "DatetimeArray", "ArrayOfTables", 省略时不检查,
+	          也可以是名称的数组, 比如 ["Integer", "Float"], 表示其中之一
+	required  元素必须存在
+	min, max  Integer, Float 以及它们的数组元素的取值范围
+	pattern   String 以及 StringArray 元素必须匹配的正则表达式
+	enum      String, Integer, Float, Boolean 以及它们的数组元素的可选值,
+	          比如 ["rw", "ro"], [1, 2, 3], Integer 和 Float 按照数值比较
+	minLen    数组或 ArrayOfTables 的最小长度
+	maxLen    数组或 ArrayOfTables 的最大长度, 0 表示不限制
+	strict    TableName 或 ArrayOfTables 的元素中不允许出现 keys 中没有声明的元素
+	keys      TableName 或 ArrayOfTables 的元素中下级元素的规则
+
+Schema 也可以由 JSON Schema 建立, 参见 FromJSONSchema, 或者由 Go struct 生成, 参见 Generate.
+*/
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/achun/tom-toml"
+)
+
+// Schema 是一个元素的规则, 根 Schema 描述整个文档, 其 Kind 为 TableName.
+type Schema struct {
+	Kind     toml.Kind   // InvalidKind 表示不检查 Kind
+	Kinds    []toml.Kind // 非空时 Kind 必须是其中之一, 此时忽略 Kind
+	Required bool
+	Min      *float64
+	Max      *float64
+	Pattern  *regexp.Regexp
+	Enum     []interface{} // 元素为 string, int64, float64 或 bool
+	MinLen   int
+	MaxLen   int // 0 表示不限制
+	Strict   bool
+	Keys     map[string]*Schema
+
+	order []string // Keys 在 schema 文档中的次序
+}
+
+// Parse 解析 TOML 格式的 schema 文档.
+func Parse(source []byte) (*Schema, error) {
+	tm, err := toml.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return FromToml(tm)
+}
+
+// LoadFile 读取并解析 TOML 格式的 schema 文件.
+func LoadFile(path string) (*Schema, error) {
+	tm, err := toml.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromToml(tm)
+}
+
+// FromToml 由已经解析的 schema 文档建立 Schema.
+func FromToml(tm toml.Toml) (*Schema, error) {
+	s, err := fromToml(tm, "")
+	if err != nil {
+		return nil, err
+	}
+	if s.Kind == toml.InvalidKind && len(s.Kinds) == 0 {
+		s.Kind = toml.TableName
+	}
+	if s.Kind != toml.TableName || len(s.Kinds) != 0 {
+		return nil, fmt.Errorf("schema: kind of the document must be TableName")
+	}
+	return s, nil
+}
+
+var rules = map[string][]toml.Kind{
+	"kind":     {toml.String, toml.StringArray},
+	"required": {toml.Boolean},
+	"min":      {toml.Integer, toml.Float},
+	"max":      {toml.Integer, toml.Float},
+	"pattern":  {toml.String},
+	"enum":     {toml.StringArray, toml.IntegerArray, toml.FloatArray, toml.BooleanArray},
+	"minLen":   {toml.Integer},
+	"maxLen":   {toml.Integer},
+	"strict":   {toml.Boolean},
+	"keys":     {toml.TableName},
+}
+
+func kindOf(name string) (toml.Kind, error) {
+	for k := toml.String; k <= toml.ArrayOfTables; k++ {
+		if k.String() == name {
+			return k, nil
+		}
+	}
+	return toml.InvalidKind, fmt.Errorf("unknown kind %q", name)
+}
+
+// fromToml 建立 prefix 所在的 Table 描述的 Schema, prefix 为 "" 或者以 "." 结尾.
+func fromToml(tm toml.Toml, prefix string) (*Schema, error) {
+	s := &Schema{}
+	seen := map[string]bool{}
+
+	for _, key := range tm.Keys() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		it := tm[key]
+		name := key[len(prefix):]
+		if strings.HasPrefix(name, "keys.") {
+			name = name[len("keys."):]
+			if pos := strings.IndexByte(name, '.'); pos != -1 {
+				name = name[:pos]
+			}
+			// 按照首次出现的次序
+			if !seen[name] {
+				seen[name] = true
+				s.order = append(s.order, name)
+			}
+			continue
+		}
+
+		kinds, ok := rules[name]
+		if !ok {
+			return nil, fmt.Errorf("schema: %s: unknown rule %q", it.Position(), prefix+name)
+		}
+		if !hasKind(kinds, it.Kind()) {
+			return nil, fmt.Errorf("schema: %s: %s must be %s", it.Position(), prefix+name, kinds[0])
+		}
+
+		var err error
+		switch name {
+		case "kind":
+			if it.Kind() == toml.String {
+				s.Kind, err = kindOf(it.String())
+				break
+			}
+			for _, name := range it.StringArray() {
+				var k toml.Kind
+				if k, err = kindOf(name); err != nil {
+					break
+				}
+				s.Kinds = append(s.Kinds, k)
+			}
+		case "required":
+			s.Required = it.Boolean()
+		case "min":
+			f := number(it.Value)
+			s.Min = &f
+		case "max":
+			f := number(it.Value)
+			s.Max = &f
+		case "pattern":
+			s.Pattern, err = regexp.Compile(it.String())
+		case "enum":
+			for i := 0; i < it.Len(); i++ {
+				s.Enum = append(s.Enum, enumOf(it.Index(i)))
+			}
+		case "minLen":
+			s.MinLen = it.Integer()
+		case "maxLen":
+			s.MaxLen = it.Integer()
+		case "strict":
+			s.Strict = it.Boolean()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("schema: %s: %s: %v", it.Position(), prefix+name, err)
+		}
+	}
+
+	if len(s.order) == 0 {
+		return s, nil
+	}
+
+	s.Keys = map[string]*Schema{}
+	for _, name := range s.order {
+		c, err := fromToml(tm, prefix+"keys."+name+".")
+		if err != nil {
+			return nil, err
+		}
+		s.Keys[name] = c
+	}
+	return s, nil
+}
+
+// names 返回 Keys 中的名称, 由 schema 文档建立时按照文档的次序, 否则按照名称排序.
+func (s *Schema) names() []string {
+	if len(s.order) == len(s.Keys) {
+		ok := true
+		for _, name := range s.order {
+			if _, ok = s.Keys[name]; !ok {
+				break
+			}
+		}
+		if ok {
+			return s.order
+		}
+	}
+
+	names := make([]string, 0, len(s.Keys))
+	for name := range s.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**
+Paths 返回 s 中可以确定 Kind 的值的访问路径及其 Kind, 包括 TableName 之下的值,
+不包括 ArrayOfTables 之下的值. 可以作为 toml.Overlay 的 Kinds.
+*/
+func (s *Schema) Paths() map[string]toml.Kind {
+	m := map[string]toml.Kind{}
+	s.paths(m, nil)
+	return m
+}
+
+func (s *Schema) paths(m map[string]toml.Kind, path []string) {
+	for name, c := range s.Keys {
+		// 多个 Kind 时无法确定
+		if len(c.Kinds) != 0 {
+			continue
+		}
+		p := subPath(path, name)
+		switch c.Kind {
+		case toml.InvalidKind, toml.ArrayOfTables:
+		case toml.TableName:
+			c.paths(m, p)
+		default:
+			m[toml.JoinPath(p)] = c.Kind
+		}
+	}
+}
+
+func hasKind(kinds []toml.Kind, kind toml.Kind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// enumOf 返回 String, Integer, Float, Boolean 对应的 Enum 元素.
+func enumOf(v *toml.Value) interface{} {
+	switch v.Kind() {
+	case toml.String:
+		return v.String()
+	case toml.Integer:
+		return v.Int()
+	case toml.Float:
+		return v.Float()
+	}
+	return v.Boolean()
+}
+
+func number(v *toml.Value) float64 {
+	if v.Kind() == toml.Integer {
+		return float64(v.Int())
+	}
+	return v.Float()
+}
@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"github.com/achun/testing-want"
+	"github.com/achun/tom-toml"
+	"testing"
+)
+
+const exampleSchema = `
+strict = true
+
+[keys.title]
+kind = "String"
+required = true
+
+[keys.owner]
+kind = "TableName"
+required = true
+
+[keys.owner.keys.name]
+kind = "String"
+pattern = "^[A-Z]"
+
+[keys.owner.keys.organization]
+[keys.owner.keys.bio]
+[keys.owner.keys.dob]
+kind = "Datetime"
+
+[keys.database]
+kind = "TableName"
+
+[keys.database.keys.ports]
+kind = "IntegerArray"
+min = 1024
+max = 65535
+minLen = 1
+maxLen = 3
+
+[keys.database.keys.connection_max]
+kind = "Integer"
+max = 1000
+
+[keys.database.keys.mode]
+kind = "String"
+enum = ["rw", "ro"]
+required = true
+
+[keys.servers]
+[keys.clients]
+
+[keys.products]
+kind = "ArrayOfTables"
+minLen = 3
+strict = true
+
+[keys.products.keys.name]
+kind = "String"
+required = true
+
+[keys.products.keys.sku]
+kind = "Float"
+`
+
+func TestParse(t *testing.T) {
+	wt := want.T(t)
+	s, err := Parse([]byte(exampleSchema))
+	wt.Nil(err)
+
+	wt.Equal(s.Kind, toml.TableName)
+	wt.True(s.Strict)
+	wt.Equal(s.names(), []string{"title", "owner", "database", "servers", "clients", "products"})
+	wt.True(s.Keys["title"].Required)
+
+	owner := s.Keys["owner"]
+	wt.Equal(owner.names(), []string{"name", "organization", "bio", "dob"})
+	wt.Equal(owner.Keys["name"].Pattern.String(), "^[A-Z]")
+	wt.Equal(owner.Keys["bio"].Kind, toml.InvalidKind)
+
+	ports := s.Keys["database"].Keys["ports"]
+	wt.Equal(ports.Kind, toml.IntegerArray)
+	wt.Equal(*ports.Min, 1024.0)
+	wt.Equal(*ports.Max, 65535.0)
+	wt.Equal(ports.MinLen, 1)
+	wt.Equal(ports.MaxLen, 3)
+	wt.Equal(s.Keys["database"].Keys["mode"].Enum, []interface{}{"rw", "ro"})
+
+	wt.Equal(s.Keys["products"].Kind, toml.ArrayOfTables)
+	wt.Equal(s.Keys["products"].names(), []string{"name", "sku"})
+
+	// 直接在 Go 中建立的 Schema 按照名称排序
+	s = &Schema{Keys: map[string]*Schema{"b": {}, "a": {}}}
+	wt.Equal(s.names(), []string{"a", "b"})
+
+	for _, src := range []string{
+		"kind = \"Table\"",
+		"kind = \"String\"",
+		"[keys.a]\nkind = 1",
+		"[keys.a]\nrequire = true",
+		"[keys.a]\npattern = \"(\"",
+		"[keys.a]\nmin = \"1\"",
+		"[keys.a]\nenum = [[1], [2]]",
+		"[keys.a]\nenum = 1",
+	} {
+		_, err = Parse([]byte(src))
+		wt.NotNil(err, src)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	wt := want.T(t)
+	s, err := Parse([]byte(exampleSchema))
+	wt.Nil(err)
+
+	wt.Equal(s.Paths(), map[string]toml.Kind{
+		"title":                   toml.String,
+		"owner.name":              toml.String,
+		"owner.dob":               toml.Datetime,
+		"database.ports":          toml.IntegerArray,
+		"database.connection_max": toml.Integer,
+		"database.mode":           toml.String,
+	})
+}
@@ -0,0 +1,257 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/achun/tom-toml"
+)
+
+// Violation 是一处不符合 Schema 的地方.
+type Violation struct {
+	Path string        // 访问路径, 比如 "products[1].sku", 参见 toml.Toml.Lookup
+	Pos  toml.Location // 元素的位置, 缺少的元素使用其所在 Table 的位置
+	Msg  string
+}
+
+func (v Violation) String() string {
+	if s := v.Pos.String(); s != "" {
+		return s + ": " + v.Path + ": " + v.Msg
+	}
+	return v.Path + ": " + v.Msg
+}
+
+func (v Violation) Error() string {
+	return v.String()
+}
+
+/**
+Validate 按照 s 校验 tm, 返回全部 Violation, 没有问题时返回 nil.
+Violation 按照 schema 的次序排列, 未声明的元素排在每个 Table 的最后.
+*/
+func Validate(tm toml.Toml, s *Schema) []Violation {
+	v := &validator{}
+	v.table(tm, "", nil, toml.Location{}, s)
+	return v.out
+}
+
+type validator struct {
+	out []Violation
+}
+
+func (v *validator) add(path []string, pos toml.Location, format string, a ...interface{}) {
+	v.out = append(v.out, Violation{toml.JoinPath(path), pos, fmt.Sprintf(format, a...)})
+}
+
+func subPath(path []string, seg string) []string {
+	return append(path[:len(path):len(path)], seg)
+}
+
+// table 校验 tm 中 prefix 之下的元素, prefix 为 "" 或者以 "." 结尾, pos 是该 Table 的位置.
+func (v *validator) table(tm toml.Toml, prefix string, path []string, pos toml.Location, s *Schema) {
+	for _, name := range s.names() {
+		key := prefix + name
+		rule := s.Keys[name]
+		it, ok := tm[key]
+		if !ok || !it.IsValid() {
+			// 没有 TableName 的上级 Table
+			if !hasChildren(tm, key+".") {
+				if rule.Required {
+					v.add(subPath(path, name), pos, "required")
+				}
+				continue
+			}
+			it = toml.GenItem(toml.TableName)
+		}
+		v.item(tm, key, subPath(path, name), it, rule)
+	}
+
+	if !s.Strict {
+		return
+	}
+	for _, name := range childNames(tm, prefix) {
+		if _, ok := s.Keys[name]; !ok {
+			key := prefix + name
+			v.add(subPath(path, name), tm[key].Position(), "not declared in schema")
+		}
+	}
+}
+
+func hasChildren(tm toml.Toml, prefix string) bool {
+	for key, it := range tm {
+		if it.IsValid() && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// childNames 返回 prefix 之下直接的子元素的名称, 按名称排序.
+func childNames(tm toml.Toml, prefix string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for key, it := range tm {
+		if !it.IsValid() || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		name := key[len(prefix):]
+		if pos := strings.IndexByte(name, '.'); pos != -1 {
+			name = name[:pos]
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isArrayKind(k toml.Kind) bool {
+	return k >= toml.StringArray && k <= toml.Array
+}
+
+// allowArray 返回 s 是否接受数组, 空数组可以是任何数组.
+func (s *Schema) allowArray() bool {
+	if isArrayKind(s.Kind) {
+		return true
+	}
+	for _, k := range s.Kinds {
+		if isArrayKind(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// allow 返回 kind 是否符合 Kind 或 Kinds.
+func (s *Schema) allow(kind toml.Kind) bool {
+	if len(s.Kinds) == 0 {
+		return s.Kind == toml.InvalidKind || s.Kind == kind
+	}
+	for _, k := range s.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// kindString 返回 Kind 或 Kinds 的名称, 用于 Violation.
+func (s *Schema) kindString() string {
+	if len(s.Kinds) == 0 {
+		return s.Kind.String()
+	}
+	names := make([]string, len(s.Kinds))
+	for i, k := range s.Kinds {
+		names[i] = k.String()
+	}
+	return strings.Join(names, " or ")
+}
+
+func (v *validator) item(tm toml.Toml, key string, path []string, it toml.Item, s *Schema) {
+	pos := it.Position()
+	kind := it.Kind()
+
+	if !s.allow(kind) && !(kind == toml.Array && it.Len() == 0 && s.allowArray()) {
+		v.add(path, pos, "want %s, got %s", s.kindString(), kind)
+		return
+	}
+
+	switch {
+	case kind == toml.TableName:
+		v.table(tm, key+".", path, pos, s)
+	case kind == toml.ArrayOfTables:
+		v.length(path, pos, it.Len(), s)
+		for i, et := range it.TomlArray() {
+			id := et.Id()
+			v.table(et, "", subPath(path, "["+strconv.Itoa(i)+"]"), id.Position(), s)
+		}
+	case isArrayKind(kind):
+		v.length(path, pos, it.Len(), s)
+		for i := 0; i < it.Len(); i++ {
+			ev := it.Index(i)
+			v.value(subPath(path, "["+strconv.Itoa(i)+"]"), ev.Position(), ev, s)
+		}
+	default:
+		v.value(path, pos, it.Value, s)
+	}
+}
+
+func (v *validator) length(path []string, pos toml.Location, n int, s *Schema) {
+	if n < s.MinLen {
+		v.add(path, pos, "length %d less than %d", n, s.MinLen)
+	}
+	if s.MaxLen > 0 && n > s.MaxLen {
+		v.add(path, pos, "length %d greater than %d", n, s.MaxLen)
+	}
+}
+
+// value 校验 String, Integer, Float, Boolean 的值, 也用于数组的元素.
+func (v *validator) value(path []string, pos toml.Location, val *toml.Value, s *Schema) {
+	switch val.Kind() {
+	case toml.Integer, toml.Float:
+		f := number(val)
+		if s.Min != nil && f < *s.Min {
+			v.add(path, pos, "%v less than %v", f, *s.Min)
+		}
+		if s.Max != nil && f > *s.Max {
+			v.add(path, pos, "%v greater than %v", f, *s.Max)
+		}
+	case toml.String:
+		str := val.String()
+		if s.Pattern != nil && !s.Pattern.MatchString(str) {
+			v.add(path, pos, "%q does not match %q", str, s.Pattern)
+		}
+	}
+
+	switch val.Kind() {
+	case toml.String, toml.Integer, toml.Float, toml.Boolean:
+		if len(s.Enum) != 0 && !inEnum(s.Enum, val) {
+			v.add(path, pos, "%s not in %s", enumString(enumOf(val)), enumList(s.Enum))
+		}
+	}
+}
+
+// inEnum 返回 val 是否是 a 中的元素, Integer 和 Float 按照数值比较.
+func inEnum(a []interface{}, val *toml.Value) bool {
+	for _, e := range a {
+		switch e := e.(type) {
+		case string:
+			if val.Kind() == toml.String && val.String() == e {
+				return true
+			}
+		case bool:
+			if val.Kind() == toml.Boolean && val.Boolean() == e {
+				return true
+			}
+		case int64:
+			if (val.Kind() == toml.Integer || val.Kind() == toml.Float) && number(val) == float64(e) {
+				return true
+			}
+		case float64:
+			if (val.Kind() == toml.Integer || val.Kind() == toml.Float) && number(val) == e {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// enumString 返回用于 Violation 的 Enum 元素, 字符串带引号.
+func enumString(e interface{}) string {
+	if str, ok := e.(string); ok {
+		return strconv.Quote(str)
+	}
+	return fmt.Sprint(e)
+}
+
+func enumList(a []interface{}) string {
+	ss := make([]string, len(a))
+	for i, e := range a {
+		ss[i] = enumString(e)
+	}
+	return "[" + strings.Join(ss, " ") + "]"
+}
@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"github.com/achun/testing-want"
+	"github.com/achun/tom-toml"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	wt := want.T(t)
+	s, err := Parse([]byte(exampleSchema))
+	wt.Nil(err)
+
+	tm, err := toml.LoadFile("../tests/example.toml")
+	wt.Nil(err)
+
+	var got []string
+	for _, v := range Validate(tm, s) {
+		got = append(got, v.String())
+	}
+	wt.Equal(got, []string{
+		"../tests/example.toml:14:18: database.connection_max: 5000 greater than 1000",
+		"../tests/example.toml:11:1: database.mode: required",
+		"../tests/example.toml:40:3: products: length 2 less than 3",
+		"../tests/example.toml:42:9: products[0].sku: want Float, got Integer",
+		"../tests/example.toml:46:9: products[1].sku: want Float, got Integer",
+		"../tests/example.toml:47:11: products[1].color: not declared in schema",
+		"../tests/example.toml:50:1: fruit: not declared in schema",
+	})
+
+	s, err = Parse([]byte(`
+[keys.a]
+kind = "TableName"
+required = true
+
+[keys.a.keys.b]
+kind = "TableName"
+
+[keys.a.keys.b.keys.tags]
+kind = "StringArray"
+enum = ["x", "y"]
+pattern = "^.$"
+maxLen = 2
+
+[keys.a.keys.b.keys.ratio]
+min = 0
+max = 1.5
+
+[keys.a.keys.b.keys.empty]
+kind = "IntegerArray"
+
+[keys.a.keys.b.keys.level]
+enum = [1, 2]
+
+[keys.c]
+required = true
+`))
+	wt.Nil(err)
+
+	tm, err = toml.Parse([]byte("[a.b]\ntags = [\"x\", \"zz\", \"y\"]\nratio = -1\nempty = []\nlevel = 3\n"))
+	wt.Nil(err)
+	got = got[:0]
+	for _, v := range Validate(tm, s) {
+		got = append(got, v.String())
+	}
+	wt.Equal(got, []string{
+		"2:8: a.b.tags: length 3 greater than 2",
+		"2:14: a.b.tags[1]: \"zz\" does not match \"^.$\"",
+		"2:14: a.b.tags[1]: \"zz\" not in [\"x\" \"y\"]",
+		"3:9: a.b.ratio: -1 less than 0",
+		"5:9: a.b.level: 3 not in [1 2]",
+		"c: required",
+	})
+
+	wt.Nil(Validate(toml.New(), &Schema{}))
+}
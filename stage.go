@@ -107,17 +107,13 @@ func (s stage) Name() string {
 	return s.String()
 }
 
+// Roles 返回的 roles 被共享, 调用者不能修改.
 func (s stage) Roles() []role {
-	roles := make([]role, len(s.roles))
-	copy(roles, s.roles)
-	return roles
-	for _, role := range roles {
-		role.Is.Reset()
-	}
-	return roles
+	return s.roles
 }
 
-func (s stage) Next(token Token, stage stager) stager {
+// 使用指针, 返回 s 时不必分配内存.
+func (s *stage) Next(token Token, stage stager) stager {
 	return s
 }
 
@@ -172,7 +168,7 @@ func (s liftStage) Next(token Token, current stager) stager {
 func (s liftStage) Must(r rune) (Status, Token, stager) {
 	status, token := s.must(r, 0, false)
 	if status == SYes || status == SYesKeep {
-		if token == tokenArrayRightBrack {
+		if token == TokenArrayRightBrack {
 			if s.closed {
 				return status, token, stageError
 			}
@@ -190,7 +186,7 @@ func (s liftStage) Must(r rune) (Status, Token, stager) {
 
 // 回退场景, stager 提供 roles,
 // back 开始应该为 nil, 由 Next 进行设置, 下一次 Next 返回 back
-// Token close 描述 toles 中要替换的 stager, TOML 中只有 tokenArrayRightBrack
+// Token close 描述 toles 中要替换的 stager, TOML 中只有 TokenArrayRightBrack
 type backStage struct {
 	stager
 	back stager
@@ -399,104 +395,139 @@ func openStage() stager {
 	return stageEmpty
 }
 
+/**
+stagePlay 驱动场景直到结束或出错.
+*/
 func stagePlay(p parser, stage stager) {
-Loop:
-	for stage != nil {
-		if stage == stageEnd {
-			break
-		}
-		if stage == stageInvalid {
-			p.Invalid(tokenError)
-			break
-		}
-		if stage == stageError {
-			p.Err(stage.String())
-			break
-		}
+	pl := player{p: p, stage: stage}
+	for pl.step() {
+	}
+}
+
+/**
+player 逐个产生 Token, 每次 step 最多调用一次 p.Token, 以便按需拉取.
+active 是尚未被排除的 role 的下标, 保持 roles 的次序, 每个字符只尝试这些 role.
+active, flag 在各个 Token 之间复用, 避免每个 Token 都分配内存.
+*/
+type player struct {
+	p      parser
+	stage  stager
+	active []int
+	flag   []int
+}
+
+// step 识别下一个 Token, 返回 false 表示已经结束或出错.
+func (pl *player) step() bool {
+	p, stage := pl.p, pl.stage
+	// 只有成功产生 Token 后才能继续
+	pl.stage = nil
+
+	if stage == nil || stage == stageEnd {
+		return false
+	}
+	if stage == stageInvalid {
+		p.Invalid(TokenError)
+		return false
+	}
+	if stage == stageError {
+		p.Err(stage.String())
+		return false
+	}
+
+	roles := stage.Roles()
+	if len(roles) == 0 {
+		p.Invalid(TokenError)
+		return false
+	}
+
+	if cap(pl.flag) < len(roles) {
+		pl.active = make([]int, 0, len(roles))
+		pl.flag = make([]int, len(roles))
+	}
+	active, flag := pl.active[:0], pl.flag[:len(roles)]
+	for i := range roles {
+		active = append(active, i)
+		flag[i] = 0
+	}
+
+	var (
+		st    Status
+		token Token // flag 是 uint 和 Token 复用
+		maybe int
+		r     rune
+	)
 
-		roles := stage.Roles()
-		skip := make([]bool, len(roles))
-		flag := make([]int, len(roles))
+	for {
 
-		if len(roles) == 0 {
-			p.Invalid(tokenError)
-			break
+		r = p.Next()
+		if r == RuneError {
+			p.Invalid(TokenRuneError)
+			return false
 		}
 
-		var (
-			st    Status
-			token Token // flag 是 uint 和 Token 复用
-			maybe int
-			r     rune
-		)
+		n := 0
+		for _, i := range active {
+			role := &roles[i]
+			st, token = role.Is(r, flag[i], maybe != 0)
+			switch st {
+			case SMaybe:
+				if flag[i] == 0 {
+					maybe++
+				}
+				flag[i] = int(token)
 
-		for {
+			case SYes, SYesKeep:
+				if st == SYesKeep {
+					p.Keep()
+				}
+				if p.Token(token) != nil {
+					return false
+				}
 
-			r = p.Next()
-			if r == RuneError {
-				p.Invalid(tokenRuneError)
-				return
-			}
-			for i, role := range roles {
-				if skip[i] {
-					continue
+				if role.Stager != nil {
+					stage = role.Stager.Next(token, stage)
 				}
-				st, token = role.Is(r, flag[i], maybe != 0)
-				switch st {
-				case SMaybe:
-					if flag[i] == 0 {
-						maybe++
-					}
-					flag[i] = int(token)
-
-				case SYes, SYesKeep:
-					if st == SYesKeep {
-						p.Keep()
-					}
-					if p.Token(token) != nil {
-						return
-					}
-
-					if role.Stager != nil {
-						stage = role.Stager.Next(token, stage)
-					}
-					continue Loop
-
-				case SNot:
-
-					if flag[i] != 0 {
-						maybe--
-					}
-					skip[i] = true
-
-				case SInvalid:
-					p.Invalid(token)
-					return
+				pl.stage = stage
+				return true
+
+			case SNot:
+
+				if flag[i] != 0 {
+					maybe--
 				}
-			}
-			if maybe != 0 && r != EOF {
 				continue
+
+			case SInvalid:
+				p.Invalid(token)
+				return false
 			}
+			active[n] = i
+			n++
+		}
+		active = active[:n]
 
-			stageName := stage.Name()
-			st, token, stage = stage.Must(r)
+		if maybe != 0 && r != EOF {
+			continue
+		}
 
-			if stage == nil || st != SYes && st != SYesKeep {
-				if st == SUnexpected {
-					p.Err("unexpercted " + token.String() + " of " + stageName)
-				} else {
-					p.Err("roles does not match one of " + stageName)
-				}
-				return
-			}
-			if st == SYesKeep {
-				p.Keep()
-			}
-			if p.Token(token) != nil {
-				return
+		stageName := stage.Name()
+		st, token, stage = stage.Must(r)
+
+		if stage == nil || st != SYes && st != SYesKeep {
+			if st == SUnexpected {
+				p.Err("unexpercted " + token.String() + " of " + stageName)
+			} else {
+				p.Err("roles does not match one of " + stageName)
 			}
-			break
+			return false
+		}
+		if st == SYesKeep {
+			p.Keep()
 		}
+		if p.Token(token) != nil {
+			return false
+		}
+		pl.stage = stage
+		return true
 	}
-
 }
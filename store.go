@@ -0,0 +1,112 @@
+package toml
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+Store 保存不可变的 Toml 快照, 可以在多个 goroutine 中安全地读取和更新.
+Toml 是 map, Set, Add 等方法直接修改其中的 Value, 因此不能在读取的同时修改.
+Store 的读取总是得到完整的快照, 修改在副本上进行, 校验成功后原子地替换快照.
+Store 的零值可以使用, 此时快照为 nil.
+
+	store := toml.NewStore(tm)
+	port := store.GetInt("database.port", 5432)
+
+	err := store.Update(func(tm toml.Toml) error {
+		return tm.Set("database.port", 5433)
+	})
+
+配合 Watcher 使用:
+
+	w.Subscribe("", func(toml.Changes) {
+		store.Replace(w.Toml())
+	})
+*/
+type Store struct {
+	// Validate 是可选的, Update 和 Replace 在发布新的快照之前调用它, 返回错误时放弃新的快照.
+	Validate func(Toml) error
+
+	v  atomic.Value // 总是保存 Toml
+	mu sync.Mutex   // 串行化 Update 和 Replace
+}
+
+// NewStore 返回以 tm 的副本为快照的 Store.
+func NewStore(tm Toml) *Store {
+	s := &Store{}
+	s.v.Store(tm.Clone())
+	return s
+}
+
+// Load 返回当前的快照, 它被所有调用者共享, 不能修改.
+func (s *Store) Load() Toml {
+	tm, _ := s.v.Load().(Toml)
+	return tm
+}
+
+// Replace 校验 tm 的副本并发布为新的快照.
+func (s *Store) Replace(tm Toml) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.publish(tm.Clone())
+}
+
+/**
+Update 以当前快照的副本调用 fn, fn 可以任意修改该副本.
+fn 和 Validate 都成功后, 该副本成为新的快照, 否则放弃修改并返回错误.
+多个 Update 依次进行, 每个 fn 都能看到之前的修改. fn 中不能调用 Update 或者 Replace.
+*/
+func (s *Store) Update(fn func(Toml) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tm := s.Load().Clone()
+	if tm == nil {
+		tm = New()
+	}
+	if err := fn(tm); err != nil {
+		return err
+	}
+	return s.publish(tm)
+}
+
+func (s *Store) publish(tm Toml) error {
+	if s.Validate != nil {
+		if err := s.Validate(tm); err != nil {
+			return err
+		}
+	}
+	s.v.Store(tm)
+	return nil
+}
+
+// GetString 返回快照中 path 对应的 String, 参见 Toml.GetString. 其他 Get 方法与此相同.
+func (s *Store) GetString(path, def string) string {
+	return s.Load().GetString(path, def)
+}
+
+func (s *Store) GetInt(path string, def int64) int64 {
+	return s.Load().GetInt(path, def)
+}
+
+func (s *Store) GetFloat(path string, def float64) float64 {
+	return s.Load().GetFloat(path, def)
+}
+
+func (s *Store) GetBool(path string, def bool) bool {
+	return s.Load().GetBool(path, def)
+}
+
+func (s *Store) GetTime(path string, def time.Time) time.Time {
+	return s.Load().GetTime(path, def)
+}
+
+func (s *Store) GetStringSlice(path string, def []string) []string {
+	return s.Load().GetStringSlice(path, def)
+}
+
+func (s *Store) GetIntSlice(path string, def []int64) []int64 {
+	return s.Load().GetIntSlice(path, def)
+}
@@ -0,0 +1,94 @@
+package toml
+
+import (
+	"errors"
+	"github.com/achun/testing-want"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	s := NewStore(tm)
+	wt.Nil(tm.Set("title", "changed"))
+	wt.Equal(s.GetString("title", ""), "TOML Example")
+	wt.Equal(s.GetString("nothing", "def"), "def")
+	wt.Equal(s.GetString("database.ports", "def"), "def")
+	wt.Equal(s.GetInt("database.connection_max", 0), int64(5000))
+	wt.Equal(s.GetInt("products[1].sku", 0), int64(284758393))
+	wt.Equal(s.GetBool("database.enabled", false), true)
+	wt.Equal(s.GetFloat("database.enabled", 1.5), 1.5)
+	wt.Equal(s.GetTime("owner.dob", time.Time{}).Year(), 1979)
+	wt.Equal(s.GetStringSlice("servers.alpha.ip", nil), []string(nil))
+	wt.Equal(s.GetIntSlice("database.ports", nil), []int64{8001, 8001, 8002})
+
+	old := s.Load()
+	wt.Nil(s.Update(func(tm Toml) error {
+		return tm.Set("database.connection_max", 100)
+	}))
+	wt.Equal(s.GetInt("database.connection_max", 0), int64(100))
+	wt.Equal(old.Get("database.connection_max").Integer(), 5000)
+
+	// fn 或者 Validate 失败时不发布
+	failed := errors.New("failed")
+	wt.Equal(s.Update(func(tm Toml) error {
+		tm.Set("database.connection_max", 1)
+		return failed
+	}), failed)
+	s.Validate = func(tm Toml) error {
+		if tm.Get("database.connection_max").Integer() < 10 {
+			return failed
+		}
+		return nil
+	}
+	wt.Equal(s.Update(func(tm Toml) error {
+		return tm.Set("database.connection_max", 1)
+	}), failed)
+	wt.Equal(s.GetInt("database.connection_max", 0), int64(100))
+
+	wt.Equal(s.Replace(tm), nil)
+	wt.Equal(s.GetString("title", ""), "changed")
+
+	// 零值
+	var zero Store
+	wt.Equal(zero.Load(), Toml(nil))
+	wt.Equal(zero.GetString("title", "def"), "def")
+	wt.Nil(zero.Update(func(tm Toml) error {
+		return tm.Set("title", "zero")
+	}))
+	wt.Equal(zero.GetString("title", ""), "zero")
+}
+
+func TestStoreConcurrent(t *testing.T) {
+	wt := want.T(t)
+	s := NewStore(nil)
+	wt.Nil(s.Update(func(tm Toml) error {
+		return tm.Set("server.count", 0)
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Update(func(tm Toml) error {
+					return tm.Set("server.count", tm.Get("server.count").Integer()+1)
+				})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = s.Load().String()
+				s.GetInt("server.count", 0)
+			}
+		}()
+	}
+	wg.Wait()
+	wt.Equal(s.GetInt("server.count", 0), int64(400))
+}
@@ -0,0 +1,144 @@
+package toml
+
+import (
+	"strconv"
+)
+
+// Position 是 source 中的位置, Offset 从 0 开始, Line 和 Column 从 1 开始, Column 以字符计.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+/**
+advance 返回越过 s 之后的位置, last 是 s 之前的字符.
+"\r\n" 和 "\n\r" 视作一个换行, 它们可能分属两个 Token.
+*/
+func (p Position) advance(s string, last rune) (Position, rune) {
+	for _, r := range s {
+		p.Column++
+		if isNewLine(r) {
+			if r == last || !isNewLine(last) {
+				p.Line++
+			}
+			p.Column = 1
+		}
+		last = r
+	}
+	p.Offset += len(s)
+	return p, last
+}
+
+/**
+TokenInfo 是一个词法单元.
+Text 是 source[Start.Offset:End.Offset] 的原始内容, 含首尾空白,
+依次连接全部 TokenInfo 的 Text 可以得到 source (不含 BOM).
+*/
+type TokenInfo struct {
+	Token Token
+	Text  string
+	Start Position
+	End   Position
+}
+
+// SyntaxError 是词法错误, Pos 是无法识别的内容的开始位置.
+type SyntaxError struct {
+	Pos Position
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return "toml: " + e.Pos.String() + ": " + e.Msg
+}
+
+/**
+Tokenize 按 source 中的次序对每个词法单元调用 handler, 最后一个是 TokenEOF.
+handler 返回的错误会终止 Tokenize 并被返回. 词法错误时返回 *SyntaxError.
+Tokenize 只识别词法, 不检查重复定义等语义错误, 适用于语法高亮和快速校验.
+这是稳定的 API.
+
+	err := toml.Tokenize(source, func(ti toml.TokenInfo) error {
+		fmt.Println(ti.Start, ti.Token, ti.Text)
+		return nil
+	})
+*/
+func Tokenize(source []byte, handler func(TokenInfo) error) error {
+	tz := NewTokenizer(source)
+	for {
+		ti, err := tz.Next()
+		if err != nil {
+			return err
+		}
+		if err = handler(ti); err != nil || ti.Token == TokenEOF {
+			return err
+		}
+	}
+}
+
+/**
+Tokenizer 以拉取的方式提供词法单元, 是 Tokenize 的另一种形式, 这是稳定的 API.
+每次 Next 只识别一个词法单元, 不会缓存整个文档的词法单元,
+错误之前的词法单元也会先被返回. 不再读取时直接丢弃即可, 无需关闭.
+
+	tz := toml.NewTokenizer(source)
+	for {
+		ti, err := tz.Next()
+		if err != nil || ti.Token == toml.TokenEOF {
+			break
+		}
+	}
+*/
+type Tokenizer struct {
+	p     *parse
+	pl    player
+	tr    tracker
+	ti    TokenInfo
+	ready bool // ti 是刚识别的词法单元
+	done  bool
+	err   error
+}
+
+// NewTokenizer 返回 source 的 Tokenizer.
+func NewTokenizer(source []byte) *Tokenizer {
+	t := &Tokenizer{
+		p:  &parse{Scanner: NewScanner(source)},
+		tr: newTracker(source),
+	}
+	t.p.Handler(t.handle)
+	t.pl = player{p: t.p, stage: openStage()}
+	return t
+}
+
+func (t *Tokenizer) handle(token Token, str string) error {
+	if token == TokenError {
+		t.err = &SyntaxError{t.tr.pos, str}
+		return t.err
+	}
+	span := t.tr.advance(t.p.raw)
+	t.ti, t.ready = TokenInfo{token, t.p.raw, span.Start, span.End}, true
+	return nil
+}
+
+/**
+Next 返回下一个词法单元. 到达末尾后总是返回 TokenEOF,
+发生词法错误后总是返回该 *SyntaxError, 之前的词法单元都已经被返回.
+*/
+func (t *Tokenizer) Next() (TokenInfo, error) {
+	for !t.done {
+		t.ready = false
+		t.done = !t.pl.step()
+		if t.ready {
+			t.done = t.done || t.ti.Token == TokenEOF
+			return t.ti, nil
+		}
+	}
+	if t.err != nil {
+		return TokenInfo{}, t.err
+	}
+	return TokenInfo{Token: TokenEOF}, nil
+}
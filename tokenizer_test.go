@@ -0,0 +1,116 @@
+package toml
+
+import (
+	"errors"
+	"github.com/achun/testing-want"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	wt := want.T(t)
+	source, err := ioutil.ReadFile("tests/example.toml")
+	wt.Nil(err)
+
+	var (
+		text []string
+		last = TokenInfo{End: Position{0, 1, 1}}
+	)
+	wt.Nil(Tokenize(source, func(ti TokenInfo) error {
+		wt.Equal(ti.Start, last.End)
+		wt.Equal(string(source[ti.Start.Offset:ti.End.Offset]), ti.Text)
+		text = append(text, ti.Text)
+		last = ti
+		return nil
+	}))
+	wt.Equal(strings.Join(text, ""), string(source))
+	wt.Equal(last.Token, TokenEOF)
+	wt.Equal(last.End.Offset, len(source))
+
+	var got []string
+	wt.Nil(Tokenize([]byte("\xEF\xBB\xBFa = 1 # c\r\n[b]\n  x = [\"y\"]\n"), func(ti TokenInfo) error {
+		if ti.Token != TokenWhitespace {
+			got = append(got, ti.Start.String()+" "+ti.Token.String()+" "+ti.Text)
+		}
+		return nil
+	}))
+	wt.Equal(got, []string{
+		"1:1 Key a",
+		"1:3 Equal =",
+		"1:5 Integer 1",
+		"1:7 Comment # c",
+		"1:10 NewLine \r",
+		"2:1 NewLine \n",
+		"2:1 TableName [b]",
+		"2:4 NewLine \n",
+		"3:3 Key x",
+		"3:5 Equal =",
+		"3:7 ArrayLeftBrack [",
+		"3:8 String \"y\"",
+		"3:11 ArrayRightBrack ]",
+		"3:12 NewLine \n",
+		"4:1 EOF ",
+	})
+
+	stop := errors.New("stop")
+	wt.Equal(Tokenize(source, func(ti TokenInfo) error { return stop }), stop)
+
+	err = Tokenize([]byte("a = 1\nb = [1, \"x\"]"), func(TokenInfo) error { return nil })
+	se, ok := err.(*SyntaxError)
+	wt.True(ok, err)
+	wt.Equal(se.Pos.Line, 2)
+}
+
+func TestTokenizer(t *testing.T) {
+	wt := want.T(t)
+	tz := NewTokenizer([]byte("a = true\n"))
+	var got []Token
+	for {
+		ti, err := tz.Next()
+		wt.Nil(err)
+		got = append(got, ti.Token)
+		if ti.Token == TokenEOF {
+			break
+		}
+	}
+	wt.Equal(got, []Token{TokenKey, TokenWhitespace, TokenEqual, TokenWhitespace, TokenBoolean, TokenNewLine, TokenEOF})
+	ti, err := tz.Next()
+	wt.Nil(err)
+	wt.Equal(ti.Token, TokenEOF)
+
+	tz = NewTokenizer([]byte("a = 1\n[b"))
+	n := 0
+	for ; ; n++ {
+		_, err = tz.Next()
+		if err != nil {
+			break
+		}
+	}
+	wt.Equal(n, 6)
+	_, err2 := tz.Next()
+	wt.Equal(err2, err)
+}
+
+func TestTokenizerStreaming(t *testing.T) {
+	wt := want.T(t)
+
+	// 错误之前的词法单元先被返回
+	tz := NewTokenizer([]byte("a = 1\n" + strings.Repeat("b = 2\n", 1000) + "[c"))
+	ti, err := tz.Next()
+	wt.Nil(err)
+	wt.Equal(ti.Token, TokenKey)
+	wt.Equal(ti.Text, "a")
+
+	n := 1
+	for {
+		if _, err = tz.Next(); err != nil {
+			break
+		}
+		n++
+	}
+	se, ok := err.(*SyntaxError)
+	wt.True(ok, err)
+	wt.Equal(se.Pos.Line, 1002)
+	wt.Equal(n, 6*1001)
+}
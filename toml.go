@@ -34,6 +34,34 @@ func (tm Toml) Id() Value {
 	return *id.Value
 }
 
+/**
+SetComments 设置 Toml 自身的多行注释, 保存在 Id 中.
+对于 ArrayOfTables 中的 Toml, 这是 [[...]] 之前的注释, 对于整个文档, 这是文档末尾的注释.
+*/
+func (tm Toml) SetComments(as []string) {
+	tm.Id()
+	tm[iD].SetComments(as)
+}
+
+// SetComment 设置 Toml 自身的行尾注释, 对于 ArrayOfTables 中的 Toml, 这是 [[...]] 的行尾注释.
+func (tm Toml) SetComment(s string) {
+	tm.Id()
+	tm[iD].SetComment(s)
+}
+
+/**
+Keys 返回全部有效元素的 Key, 按照 Id 排序, 也就是在文档中出现或者被建立的次序.
+不包括 ArrayOfTables 中的元素, 它们属于各自的 Toml.
+*/
+func (p Toml) Keys() []string {
+	ks := p.validKeys()
+	keys := make([]string, len(ks))
+	for i, k := range ks {
+		keys[i] = k.key
+	}
+	return keys
+}
+
 // String returns TOML layout string.
 // 格式化输出带缩进的 TOML 格式.
 func (p Toml) String() string {
@@ -139,12 +167,8 @@ func (p Toml) string(prefix string, indent int) (fmt string) {
 		fmt += indentstr + kv.key + " = " + it.string(indentstr, 1)
 
 		if it.eolComment != "" {
-			fmt += " " + it.eolComment + "\n"
+			fmt += " " + it.eolComment
 		}
-
-	}
-
-	if len(tops) != 0 {
 		fmt += "\n"
 	}
 
@@ -304,10 +328,11 @@ func (p Toml) Apply(dst interface{}) (count int) {
 	} else {
 		vv = reflect.Indirect(reflect.ValueOf(dst))
 	}
-	return p.apply(vv)
+	return p.apply(vv, nil, "")
 }
 
-func (p Toml) apply(vv reflect.Value) (count int) {
+// apply 的 c 不为 nil 时按照 c.Policy 转换类型, prefix 是 p 的访问路径, 用于 Coercion.
+func (p Toml) apply(vv reflect.Value, c *Lenient, prefix string) (count int) {
 
 	var it Item
 	vt := vv.Type()
@@ -319,14 +344,19 @@ func (p Toml) apply(vv reflect.Value) (count int) {
 		name := vt.Field(i).Name
 		it = p[name]
 
-		if !it.IsValid() {
+		// 未导出的字段不能赋值
+		if !it.IsValid() || !vv.Field(i).CanSet() {
 			continue
 		}
 
+		path := JoinPath([]string{name})
+		if prefix != "" {
+			path = prefix + "." + path
+		}
 		if it.kind == TableName {
-			count += p.Fetch(name).Apply(vv.Field(i))
+			count += p.Fetch(name).apply(reflect.Indirect(vv.Field(i)), c, path)
 		} else {
-			count += it.apply(vv.Field(i))
+			count += it.apply(vv.Field(i), c, path)
 		}
 	}
 	return
@@ -339,12 +369,27 @@ var (
 
 // 从 TOML 格式 source 解析出 Toml 对象.
 func Parse(source []byte) (tm Toml, err error) {
+	return parseSource(source, "", nil)
+}
+
+// parseSource 解析 source, file 用于 Value.Position, lm 不为 nil 时检查 Limits.
+func parseSource(source []byte, file string, lm *limiter) (tm Toml, err error) {
 	p := &parse{Scanner: NewScanner(source)}
+	tr := newTracker(source)
 
 	tb := newBuilder(nil)
+	tb.root.file = file
 
 	p.Handler(
 		func(token Token, str string) error {
+			if token != TokenError {
+				tb.root.span = tr.trimmed(p.raw, str)
+				if lm != nil {
+					if err = lm.check(token, str, tb.root.span); err != nil {
+						return err
+					}
+				}
+			}
 			tb, err = tb.Token(token, str)
 			return err
 		})
@@ -366,6 +411,8 @@ type tomlBuilder struct {
 	tableName string  // cache tableName
 	prefix    string  // with "." for nested TOML
 	token     Token   // 有些时候需要知道上一个 token, 比如尾注释
+	span      Span    // 当前 token 的位置, 只在 root 中使用
+	file      string  // 文件名, 只在 root 中使用
 }
 
 func newBuilder(root *tomlBuilder) tomlBuilder {
@@ -386,55 +433,76 @@ func (t tomlBuilder) Toml() Toml {
 	return t.tm
 }
 
+// location 返回当前 token 作为 Key 或者 TableName 的位置.
+func (t tomlBuilder) location() *Location {
+	return &Location{File: t.root.file, Key: t.root.span}
+}
+
+// located 在 err 为 nil 时记录刚刚设置的值或者数组元素的位置.
+func (t tomlBuilder) located(err error) (tomlBuilder, error) {
+	if err != nil {
+		return t, err
+	}
+	if !isArrayKind(t.iv.kind) {
+		if t.iv.loc != nil {
+			t.iv.loc.Value = t.root.span
+		}
+		return t, nil
+	}
+	a := t.iv.v.([]*Value)
+	a[len(a)-1].loc = &Location{File: t.root.file, Value: t.root.span}
+	return t, nil
+}
+
 func (t tomlBuilder) Token(token Token, str string) (tomlBuilder, error) {
 	defer func() {
 		// 缓存上一个 token, eolComment 等需要用
-		if token == tokenWhitespace {
+		if token == TokenWhitespace {
 			return
 		}
 
 		t.root.token = token
 
-		if token != tokenComment && token != tokenNewLine {
+		if token != TokenComment && token != TokenNewLine {
 			t.token = token
 		}
 	}()
 	switch token {
-	case tokenError:
+	case TokenError:
 		return t.Error(str)
-	case tokenRuneError:
+	case TokenRuneError:
 		return t.RuneError(str)
-	case tokenEOF:
+	case TokenEOF:
 		return t.EOF(str)
-	case tokenWhitespace:
+	case TokenWhitespace:
 		return t.Whitespace(str)
-	case tokenEqual:
+	case TokenEqual:
 		return t.Equal(str)
-	case tokenNewLine:
+	case TokenNewLine:
 		return t.NewLine(str)
-	case tokenComment:
+	case TokenComment:
 		return t.Comment(str)
-	case tokenString:
+	case TokenString:
 		return t.String(str)
-	case tokenInteger:
+	case TokenInteger:
 		return t.Integer(str)
-	case tokenFloat:
+	case TokenFloat:
 		return t.Float(str)
-	case tokenBoolean:
+	case TokenBoolean:
 		return t.Boolean(str)
-	case tokenDatetime:
+	case TokenDatetime:
 		return t.Datetime(str)
-	case tokenTableName:
+	case TokenTableName:
 		return t.TableName(str)
-	case tokenArrayOfTables:
+	case TokenArrayOfTables:
 		return t.ArrayOfTables(str)
-	case tokenKey:
+	case TokenKey:
 		return t.Key(str)
-	case tokenArrayLeftBrack: // [
+	case TokenArrayLeftBrack: // [
 		return t.ArrayLeftBrack(str)
-	case tokenArrayRightBrack: // ]
+	case TokenArrayRightBrack: // ]
 		return t.ArrayRightBrack(str)
-	case tokenComma:
+	case TokenComma:
 		return t.Comma(str)
 	}
 	return t, NotSupported
@@ -463,14 +531,14 @@ func (t tomlBuilder) NewLine(str string) (tomlBuilder, error) {
 func (t tomlBuilder) Comment(str string) (tomlBuilder, error) {
 
 	// eolComment
-	if t.root.token != tokenEOF && t.root.token != tokenNewLine {
+	if t.root.token != TokenEOF && t.root.token != TokenNewLine {
 
 		if len(t.comments) != 0 {
 			return t, InternalError
 		}
 
 		// [[aot]] #comment, save to iD.eolComment
-		if t.root.token == tokenArrayOfTables {
+		if t.root.token == TokenArrayOfTables {
 			id, ok := t.tm[iD]
 			if !ok || id.eolComment != "" {
 				return t, InternalError
@@ -512,9 +580,9 @@ func (t tomlBuilder) String(str string) (tomlBuilder, error) {
 	}
 
 	if t.iv.kind != Array && t.iv.kind != StringArray {
-		return t, t.iv.SetAs(str, String)
+		return t.located(t.iv.SetAs(str, String))
 	}
-	return t, t.iv.Add(str)
+	return t.located(t.iv.Add(str))
 }
 
 func (t tomlBuilder) Integer(str string) (tomlBuilder, error) {
@@ -523,13 +591,13 @@ func (t tomlBuilder) Integer(str string) (tomlBuilder, error) {
 	}
 
 	if t.iv.kind != Array && t.iv.kind != IntegerArray {
-		return t, t.iv.SetAs(str, Integer)
+		return t.located(t.iv.SetAs(str, Integer))
 	}
 	v, err := conv(str, Integer)
 	if err != nil {
 		return t, err
 	}
-	return t, t.iv.Add(v)
+	return t.located(t.iv.Add(v))
 }
 func (t tomlBuilder) Float(str string) (tomlBuilder, error) {
 	if t.iv == nil {
@@ -537,13 +605,13 @@ func (t tomlBuilder) Float(str string) (tomlBuilder, error) {
 	}
 
 	if t.iv.kind != Array && t.iv.kind != FloatArray {
-		return t, t.iv.SetAs(str, Float)
+		return t.located(t.iv.SetAs(str, Float))
 	}
 	v, err := conv(str, Float)
 	if err != nil {
 		return t, err
 	}
-	return t, t.iv.Add(v)
+	return t.located(t.iv.Add(v))
 }
 func (t tomlBuilder) Boolean(str string) (tomlBuilder, error) {
 	if t.iv == nil {
@@ -551,13 +619,13 @@ func (t tomlBuilder) Boolean(str string) (tomlBuilder, error) {
 	}
 
 	if t.iv.kind != Array && t.iv.kind != BooleanArray {
-		return t, t.iv.SetAs(str, Boolean)
+		return t.located(t.iv.SetAs(str, Boolean))
 	}
 	v, err := conv(str, Boolean)
 	if err != nil {
 		return t, err
 	}
-	return t, t.iv.Add(v)
+	return t.located(t.iv.Add(v))
 }
 func (t tomlBuilder) Datetime(str string) (tomlBuilder, error) {
 	if t.iv == nil {
@@ -565,13 +633,13 @@ func (t tomlBuilder) Datetime(str string) (tomlBuilder, error) {
 	}
 
 	if t.iv.kind != Array && t.iv.kind != DatetimeArray {
-		return t, t.iv.SetAs(str, Datetime)
+		return t.located(t.iv.SetAs(str, Datetime))
 	}
 	v, err := conv(str, Datetime)
 	if err != nil {
 		return t, err
 	}
-	return t, t.iv.Add(v)
+	return t.located(t.iv.Add(v))
 }
 
 func (t tomlBuilder) TableName(str string) (tomlBuilder, error) {
@@ -606,6 +674,7 @@ func (t tomlBuilder) TableName(str string) (tomlBuilder, error) {
 	t.tableName = path
 
 	it = GenItem(TableName)
+	it.loc = t.location()
 
 	it.multiComments = append(it.multiComments, comments...)
 
@@ -618,6 +687,7 @@ func (t tomlBuilder) TableName(str string) (tomlBuilder, error) {
 func (t tomlBuilder) Key(str string) (tomlBuilder, error) {
 
 	it := GenItem(0)
+	it.loc = t.location()
 
 	it.multiComments, t.comments = t.comments, aString{}
 
@@ -633,7 +703,7 @@ func (t tomlBuilder) Key(str string) (tomlBuilder, error) {
 
 func (t tomlBuilder) Equal(str string) (tomlBuilder, error) {
 
-	if t.root.token != tokenKey {
+	if t.root.token != TokenKey {
 		return t, InValidFormat
 	}
 
@@ -689,11 +759,13 @@ func (t tomlBuilder) nestToml(prefix string) (tomlBuilder, error) {
 
 	// Comments
 	id.multiComments, t.comments = t.comments, aString{}
+	id.loc = t.location()
 
 	// first [[...]]
 	if !ok {
 		it = GenItem(ArrayOfTables)
 		it.v = TomlArray{tb.tm}
+		it.loc = t.location()
 		t.tm[prefix] = it
 
 	} else {
@@ -712,6 +784,9 @@ func (t tomlBuilder) ArrayLeftBrack(str string) (tomlBuilder, error) {
 
 	if t.iv.kind == InvalidKind {
 		t.iv.kind = Array
+		if t.iv.loc != nil {
+			t.iv.loc.Value.Start = t.root.span.Start
+		}
 		return t, nil
 	}
 	if t.iv.kind != Array {
@@ -720,6 +795,7 @@ func (t tomlBuilder) ArrayLeftBrack(str string) (tomlBuilder, error) {
 
 	nt := t
 	nt.iv = NewValue(Array)
+	nt.iv.loc = &Location{File: t.root.file, Value: Span{Start: t.root.span.Start}}
 	nt.p = &t
 	t.iv.Add(nt.iv)
 	return nt, nil
@@ -731,6 +807,10 @@ func (t tomlBuilder) ArrayRightBrack(str string) (tomlBuilder, error) {
 		return t, InValidFormat
 	}
 
+	if t.iv.loc != nil {
+		t.iv.loc.Value.End = t.root.span.End
+	}
+
 	if t.p == nil {
 		return t, nil
 	}
@@ -752,6 +832,6 @@ func LoadFile(path string) (toml Toml, err error) {
 	if err != nil {
 		return
 	}
-	toml, err = Parse(source)
+	toml, err = parseSource(source, path, nil)
 	return
 }
@@ -325,6 +325,39 @@ func wantExample(wt want.Want, tm Toml) {
 	wt.Equal(it.eolComment, "")
 }
 
+func TestTomlString(t *testing.T) {
+	wt := want.T(t)
+
+	// 单个顶层 Key 的输出不变
+	tm, err := Parse([]byte("title = \"x\"\n[owner]\nname = \"Tom\"\n"))
+	wt.Nil(err)
+	wt.Equal(tm.String(), "title = \"x\"\n\n[owner]\n\tname = \"Tom\"\n")
+
+	// 多个顶层 Key 各占一行, 曾经被输出为 "a = 1b = 2"
+	tm, err = Parse([]byte("a = 1\nb = 2\n"))
+	wt.Nil(err)
+	wt.Equal(tm.String(), "a = 1\nb = 2\n")
+
+	// 行尾注释之后不再多出空行
+	tm, err = Parse([]byte("a = 1\nb = 2 # two\n[t]\nc = 3\n"))
+	wt.Nil(err)
+	wt.Equal(tm.String(), "a = 1\nb = 2 # two\n\n[t]\n\tc = 3\n")
+
+	// ArrayOfTables 中的值也是如此
+	tm, err = Parse([]byte("[[p]]\nname = \"Hammer\"\nsku = 1\n"))
+	wt.Nil(err)
+	wt.Equal(tm.String(), "\n[[p]]\n\tname = \"Hammer\"\n\tsku = 1\n")
+}
+
+func TestTomlKeys(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte("b = 1\na = 2\n[t]\nz = 3\n[[p]]\nx = 4\n"))
+	wt.Nil(err)
+	wt.Nil(tm.Set("c", 5))
+	wt.Equal(tm.Keys(), []string{"b", "a", "t", "t.z", "p", "c"})
+	wt.Equal(New().Keys(), []string{})
+}
+
 func TestTomlEmpty(t *testing.T) {
 	if skipTest {
 		return
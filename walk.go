@@ -0,0 +1,176 @@
+package toml
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// WalkFunc 返回 SkipSubtree 时, Walk 跳过当前 TableName, ArrayOfTables 或数组的下属元素.
+var SkipSubtree = errors.New("skip subtree")
+
+/**
+WalkFunc 是 Walk 访问每个元素时调用的函数.
+path 是元素的路径, 每一节是一个 key, ArrayOfTables 和数组的下标表示为 "[0]" 这样的一节,
+JoinPath(path) 得到可以用于 Get, Set 的访问路径. depth 是嵌套的层数, 顶层元素为 0.
+*/
+type WalkFunc func(path []string, item Item, depth int) error
+
+/**
+Walk 按照文档的次序访问 p 中的全部元素, 包括 TableName, Key-Value,
+ArrayOfTables 以及它的每一个 Table, 数组以及它的每一个元素.
+对于包含下属元素的 TableName, ArrayOfTables, 其中的 Table 以及数组,
+先以 enter 进入, 访问全部下属元素后以 leave 离开, leave 是可选的.
+
+	tm.Walk(func(path []string, item Item, depth int) error {
+		fmt.Println(strings.Repeat("  ", depth), JoinPath(path), item.Kind())
+		return nil
+	})
+
+注意:
+	没有 TableName 的上级 Table, 比如只有 [a.b] 时的 a, 也以 TableName 访问, 其 Id() 为 0.
+	ArrayOfTables 中的 Table 以 TableName 访问, 它是该 Table 的 Id 的副本, 含有 [[...]] 的注释.
+	enter 返回 SkipSubtree 时跳过下属元素, 也不会调用 leave.
+	enter 或 leave 返回其他错误时 Walk 停止并返回该错误.
+*/
+func (p Toml) Walk(enter WalkFunc, leave ...WalkFunc) error {
+	w := walker{enter: enter}
+	if len(leave) != 0 {
+		w.leave = leave[0]
+	}
+	return w.table(p, p.outline(), "", nil, 0)
+}
+
+// JoinPath 把 WalkFunc 的 path 连接为访问路径, 参见 Lookup.
+func JoinPath(path []string) string {
+	s := ""
+	for _, seg := range path {
+		switch {
+		case isIndexSeg(seg):
+			s += seg
+		case s == "":
+			s = quoteKey(seg)
+		default:
+			s += "." + quoteKey(seg)
+		}
+	}
+	return s
+}
+
+// quoteKey 在 key 含有路径分隔符等字符时用双引号包裹它.
+func quoteKey(key string) string {
+	if key == "" || strings.IndexAny(key, ".[]\" \t\r\n") != -1 {
+		return strconv.Quote(key)
+	}
+	return key
+}
+
+// isIndexSeg 返回 seg 是否为 "[0]" 形式的下标.
+func isIndexSeg(seg string) bool {
+	if len(seg) < 3 || seg[0] != '[' || seg[len(seg)-1] != ']' {
+		return false
+	}
+	_, err := strconv.Atoi(seg[1 : len(seg)-1])
+	return err == nil
+}
+
+/**
+outline 返回每个前缀之下按文档次序排列的直接子元素名称.
+前缀以 "." 结尾, 顶层的前缀为 "".
+*/
+func (p Toml) outline() map[string][]string {
+	m := map[string][]string{}
+	seen := map[string]bool{}
+	for _, k := range p.validKeys() {
+		prefix := ""
+		for _, name := range strings.Split(k.key, ".") {
+			key := prefix + name
+			if !seen[key] {
+				seen[key] = true
+				m[prefix] = append(m[prefix], name)
+			}
+			prefix = key + "."
+		}
+	}
+	return m
+}
+
+type walker struct {
+	enter WalkFunc
+	leave WalkFunc
+}
+
+// 每次调用都使用新的 path, 以免调用者保存的 path 被修改.
+func subPath(path []string, seg string) []string {
+	return append(path[:len(path):len(path)], seg)
+}
+
+func (w walker) table(tm Toml, outline map[string][]string, prefix string, path []string, depth int) error {
+	for _, name := range outline[prefix] {
+		key := prefix + name
+		it, ok := tm[key]
+		if !ok || !it.IsValid() {
+			it = Item{&Value{kind: TableName}}
+		}
+
+		err := w.item(tm, outline, key, subPath(path, name), it, depth)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w walker) item(tm Toml, outline map[string][]string, key string, path []string, it Item, depth int) error {
+	err := w.enter(path, it, depth)
+	if err == SkipSubtree {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case it.kind == TableName:
+		// ArrayOfTables 中的 Table 的 key 为 ""
+		if key != "" {
+			key += "."
+		}
+		err = w.table(tm, outline, key, path, depth+1)
+
+	case it.kind == ArrayOfTables:
+		for i, elem := range it.TomlArray() {
+			id := elem[iD].Value
+			if id == nil {
+				id = &Value{}
+			}
+			eit := Item{&Value{
+				kind:          TableName,
+				idx:           id.idx,
+				eolComment:    id.eolComment,
+				multiComments: id.multiComments,
+			}}
+			err = w.item(elem, elem.outline(), "", subPath(path, "["+strconv.Itoa(i)+"]"), eit, depth+1)
+			if err != nil {
+				break
+			}
+		}
+
+	case isArrayKind(it.kind):
+		a, _ := it.v.([]*Value)
+		for i, v := range a {
+			err = w.item(nil, nil, "", subPath(path, "["+strconv.Itoa(i)+"]"), Item{v}, depth+1)
+			if err != nil {
+				break
+			}
+		}
+
+	default:
+		return nil
+	}
+
+	if err != nil || w.leave == nil {
+		return err
+	}
+	return w.leave(path, it, depth)
+}
@@ -0,0 +1,121 @@
+package toml
+
+import (
+	"errors"
+	"github.com/achun/testing-want"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTomlWalk(t *testing.T) {
+	wt := want.T(t)
+	tm, err := LoadFile("tests/example.toml")
+	wt.Nil(err)
+
+	var got []string
+	enter := func(path []string, it Item, depth int) error {
+		got = append(got, strings.Repeat(" ", depth)+JoinPath(path)+" "+it.Kind().String())
+		return nil
+	}
+	leave := func(path []string, it Item, depth int) error {
+		got = append(got, strings.Repeat(" ", depth)+"/"+JoinPath(path))
+		return nil
+	}
+
+	wt.Nil(tm.Walk(enter, leave))
+	wt.Equal(strings.Join(got[:12], "\n"), strings.Join([]string{
+		"title String",
+		"owner TableName",
+		" owner.name String",
+		" owner.organization String",
+		" owner.bio String",
+		" owner.dob Datetime",
+		"/owner",
+		"database TableName",
+		" database.server String",
+		" database.ports IntegerArray",
+		"  database.ports[0] Integer",
+		"  database.ports[1] Integer",
+	}, "\n"))
+	wt.Equal(strings.Join(got[len(got)-24:len(got)-8], "\n"), strings.Join([]string{
+		" fruit[0] TableName",
+		"  fruit[0].name String",
+		"  fruit[0].physical TableName",
+		"   fruit[0].physical.color String",
+		"   fruit[0].physical.shape String",
+		"  /fruit[0].physical",
+		"  fruit[0].variety ArrayOfTables",
+		"   fruit[0].variety[0] TableName",
+		"    fruit[0].variety[0].name String",
+		"   /fruit[0].variety[0]",
+		"   fruit[0].variety[1] TableName",
+		"    fruit[0].variety[1].name String",
+		"   /fruit[0].variety[1]",
+		"  /fruit[0].variety",
+		" /fruit[0]",
+		" fruit[1] TableName",
+	}, "\n"))
+
+	// 每个 path 都可以访问到对应的元素
+	wt.Nil(tm.Walk(func(path []string, it Item, depth int) error {
+		p := JoinPath(path)
+		if v, ok := tm.Lookup(p); ok {
+			wt.True(v.Value == it.Value, p)
+		} else {
+			_, ok = tm.LookupTable(p)
+			wt.True(ok, p)
+		}
+		return nil
+	}))
+
+	// SkipSubtree
+	got = got[:0]
+	wt.Nil(tm.Walk(func(path []string, it Item, depth int) error {
+		if it.Kind() >= StringArray {
+			got = append(got, JoinPath(path))
+			return SkipSubtree
+		}
+		return nil
+	}, leave))
+	wt.Equal(got, []string{"owner", "database", "servers", "clients", "products", "fruit"})
+
+	// 错误
+	stop := errors.New("stop")
+	n := 0
+	wt.Equal(tm.Walk(func(path []string, it Item, depth int) error {
+		n++
+		if JoinPath(path) == "database" {
+			return stop
+		}
+		return nil
+	}), stop)
+	wt.Equal(n, 7)
+}
+
+func TestTomlWalkImplicit(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte("[a.b]\nc = 1\n[[d]]\n[[d]] # second\ne = [[1], [2]]\n"))
+	wt.Nil(err)
+
+	var got []string
+	wt.Nil(tm.Walk(func(path []string, it Item, depth int) error {
+		got = append(got, strconv.Itoa(depth)+" "+JoinPath(path)+" "+it.Kind().String()+it.Comment())
+		return nil
+	}))
+	wt.Equal(got, []string{
+		"0 a TableName",
+		"1 a.b TableName",
+		"2 a.b.c Integer",
+		"0 d ArrayOfTables",
+		"1 d[0] TableName",
+		"1 d[1] TableName# second",
+		"2 d[1].e Array",
+		"3 d[1].e[0] IntegerArray",
+		"4 d[1].e[0][0] Integer",
+		"3 d[1].e[1] IntegerArray",
+		"4 d[1].e[1][0] Integer",
+	})
+	wt.Equal(JoinPath([]string{"a b", "[1]", "c"}), `"a b"[1].c`)
+	wt.Equal(JoinPath([]string{"[x", "[1]"}), `"[x"[1]`)
+}
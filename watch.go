@@ -0,0 +1,215 @@
+package toml
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatchOptions 是 NewWatcher 的参数, 零值表示每秒检查一次, 使用 LoadFile 载入, 不校验.
+type WatchOptions struct {
+	Interval time.Duration              // 检查文件的间隔, 0 表示 time.Second
+	Load     func(string) (Toml, error) // 载入文件的函数, nil 表示 LoadFile, 也可以是 Loader.LoadFile
+	Validate func(Toml) error           // 可选, 返回错误时不使用新的 Toml
+	OnError  func(error)                // 可选, 轮询时重新载入失败会调用它
+}
+
+/**
+Watcher 定期检查文件的修改时间和大小, 文件改变后重新载入,
+载入和校验都成功后才替换当前的 Toml, 并把 Diff 的结果发送给订阅者.
+载入失败时保留原来的 Toml, 直到文件再次改变.
+使用 Loader.LoadFile 时只检查 path 本身, 被包含的文件的改变不会被发现.
+
+	w, err := toml.NewWatcher("app.toml", toml.WatchOptions{})
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	w.Subscribe("database", func(cs toml.Changes) {
+		reconnect(w.Toml())
+	})
+*/
+type Watcher struct {
+	path string
+	opts WatchOptions
+
+	reload sync.Mutex // 串行化载入和通知
+	mu     sync.Mutex // 保护以下字段
+	tm     Toml
+	stat   os.FileInfo
+	subs   []subscriber
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type subscriber struct {
+	prefix string
+	fn     func(Changes)
+}
+
+// NewWatcher 载入 path 并开始在后台检查, 首次载入或校验失败时返回错误.
+func NewWatcher(path string, opts WatchOptions) (*Watcher, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Load == nil {
+		opts.Load = LoadFile
+	}
+
+	w := &Watcher{
+		path: path,
+		opts: opts,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.tm, w.stat = tm, stat
+
+	go w.run()
+	return w, nil
+}
+
+// Toml 返回当前的 Toml, 它被 Watcher 和其他调用者共享, 不要修改它.
+func (w *Watcher) Toml() Toml {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tm
+}
+
+/**
+Subscribe 订阅 prefix 之下的改变, prefix 是访问路径, 比如 "database", "products[0]",
+"" 表示全部. 每次替换 Toml 后, 如果有 Change.Path 等于 prefix 或者在其之下,
+在后台检查或者 Reload 的 goroutine 中以这些 Change 调用 fn, 调用时 Toml() 已经是新的 Toml.
+*/
+func (w *Watcher) Subscribe(prefix string, fn func(Changes)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs = append(w.subs, subscriber{prefix, fn})
+}
+
+// Close 停止检查, 可以多次调用.
+func (w *Watcher) Close() {
+	w.mu.Lock()
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+	w.mu.Unlock()
+	<-w.done
+}
+
+/**
+Reload 立即重新载入文件, 不论文件是否改变, 成功时返回 nil, 失败时保留原来的 Toml.
+它与后台的检查依次进行, 订阅者同样会收到改变, 因此不能在订阅者中调用.
+*/
+func (w *Watcher) Reload() error {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+
+	stat, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	return w.swap(stat)
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.check(); err != nil && w.opts.OnError != nil {
+				w.opts.OnError(err)
+			}
+		}
+	}
+}
+
+// check 在文件的修改时间或大小改变时重新载入.
+func (w *Watcher) check() error {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+
+	stat, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	old := w.stat
+	w.mu.Unlock()
+	if stat.ModTime().Equal(old.ModTime()) && stat.Size() == old.Size() {
+		return nil
+	}
+	return w.swap(stat)
+}
+
+func (w *Watcher) load() (Toml, error) {
+	tm, err := w.opts.Load(w.path)
+	if err != nil {
+		return nil, err
+	}
+	if w.opts.Validate != nil {
+		if err = w.opts.Validate(tm); err != nil {
+			return nil, err
+		}
+	}
+	return tm, nil
+}
+
+// swap 载入文件, 成功后替换 Toml 并通知订阅者. 失败时也记录 stat, 以免重复载入.
+func (w *Watcher) swap(stat os.FileInfo) error {
+	tm, err := w.load()
+
+	w.mu.Lock()
+	w.stat = stat
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	cs := Diff(w.tm, tm)
+	w.tm = tm
+	subs := w.subs
+	w.mu.Unlock()
+
+	if len(cs) == 0 {
+		return nil
+	}
+	for _, sub := range subs {
+		if sc := cs.under(sub.prefix); len(sc) != 0 {
+			sub.fn(sc)
+		}
+	}
+	return nil
+}
+
+// under 返回 Path 等于 prefix 或者在其之下的 Change.
+func (cs Changes) under(prefix string) Changes {
+	if prefix == "" {
+		return cs
+	}
+	var sc Changes
+	for _, c := range cs {
+		if c.Path == prefix || strings.HasPrefix(c.Path, prefix) &&
+			(c.Path[len(prefix)] == '.' || c.Path[len(prefix)] == '[') {
+			sc = append(sc, c)
+		}
+	}
+	return sc
+}
@@ -0,0 +1,79 @@
+package toml
+
+import (
+	"errors"
+	"github.com/achun/testing-want"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	wt := want.T(t)
+
+	dir, err := ioutil.TempDir("", "watch")
+	wt.Nil(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "app.toml")
+	wt.Nil(ioutil.WriteFile(file, []byte("title = \"a\"\n[database]\nport = 1\n"), 0644))
+
+	invalid := errors.New("port must be positive")
+	w, err := NewWatcher(file, WatchOptions{
+		Interval: 10 * time.Millisecond,
+		Validate: func(tm Toml) error {
+			if tm.Get("database.port").Integer() <= 0 {
+				return invalid
+			}
+			return nil
+		},
+	})
+	wt.Nil(err)
+	defer w.Close()
+
+	all := make(chan Changes, 10)
+	db := make(chan Changes, 10)
+	w.Subscribe("", func(cs Changes) { all <- cs })
+	w.Subscribe("database", func(cs Changes) { db <- cs })
+
+	// 解析失败和校验失败都保留原来的 Toml
+	wt.Nil(ioutil.WriteFile(file, []byte("title = "), 0644))
+	wt.NotNil(w.Reload())
+	wt.Nil(ioutil.WriteFile(file, []byte("title = \"a\"\n[database]\nport = 0\n"), 0644))
+	wt.Equal(w.Reload(), invalid)
+	wt.Equal(w.Toml().Get("database.port").Integer(), 1)
+
+	wt.Nil(ioutil.WriteFile(file, []byte("title = \"b\"\n[database]\nport = 1\n"), 0644))
+	wt.Nil(w.Reload())
+	wt.Equal(w.Toml().Get("title").String(), "b")
+	wt.Equal((<-all).String(), "- title = \"a\"\n+ title = \"b\"\n")
+	wt.Equal(len(db), 0)
+
+	// 后台检查
+	wt.Nil(ioutil.WriteFile(file, []byte("title = \"b\"\n[database]\nport = 2\n"), 0644))
+	wt.Nil(os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)))
+	select {
+	case cs := <-db:
+		wt.Equal(cs.String(), "- database.port = 1\n+ database.port = 2\n")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	wt.Equal(len((<-all)), 1)
+	wt.Equal(w.Toml().Get("database.port").Integer(), 2)
+
+	w.Close()
+	w.Close()
+
+	_, err = NewWatcher(filepath.Join(dir, "nothing.toml"), WatchOptions{})
+	wt.True(os.IsNotExist(err))
+}
+
+func TestChangesUnder(t *testing.T) {
+	wt := want.T(t)
+	cs := Changes{{Path: "db"}, {Path: "db.port"}, {Path: "dbx"}, {Path: "db[0].a"}, {Path: "x.db"}}
+	wt.Equal(len(cs.under("")), 5)
+	wt.Equal(cs.under("db"), Changes{{Path: "db"}, {Path: "db.port"}, {Path: "db[0].a"}})
+	wt.Equal(cs.under("db[0]"), Changes{{Path: "db[0].a"}})
+	wt.Equal(len(cs.under("nothing")), 0)
+}
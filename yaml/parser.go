@@ -0,0 +1,624 @@
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	scalarNode = iota
+	mappingNode
+	sequenceNode
+)
+
+// node 是解析 YAML 得到的树, mapping 的 keys 与 children 一一对应.
+type node struct {
+	kind     int
+	value    interface{}
+	keys     []string
+	children []*node
+	comments []string
+	eol      string
+	line     int
+}
+
+type line struct {
+	n       int
+	indent  int
+	text    string // 去掉缩进和注释的内容
+	comment string
+	raw     string
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+func (p *parser) errorf(n int, format string, a ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: "+format, append([]interface{}{n}, a...)...)
+}
+
+func newParser(source []byte) (*parser, error) {
+	p := &parser{}
+	src := strings.Replace(string(source), "\r\n", "\n", -1)
+	content, end := false, false
+	for i, raw := range strings.Split(src, "\n") {
+		l := line{n: i + 1, raw: raw}
+		text := strings.TrimLeft(raw, " ")
+		l.indent = len(raw) - len(text)
+		if strings.HasPrefix(text, "\t") && strings.TrimSpace(text) != "" {
+			return nil, p.errorf(l.n, "tabs are not allowed for indentation")
+		}
+		l.text, l.comment = splitComment(text)
+
+		// 只支持一个文档, "---" 只能出现在内容之前, "..." 之后只能有注释
+		if end && l.text != "" {
+			return nil, p.errorf(l.n, "multiple documents are not supported")
+		}
+		if l.indent == 0 && (l.text == "---" || strings.HasPrefix(l.text, "--- ")) {
+			if content || l.text != "---" {
+				return nil, p.errorf(l.n, "multiple documents are not supported")
+			}
+			continue
+		}
+		if l.indent == 0 && strings.HasPrefix(l.text, "%") {
+			if content {
+				return nil, p.errorf(l.n, "unexpected directive")
+			}
+			continue
+		}
+		if l.indent == 0 && l.text == "..." {
+			end = true
+			continue
+		}
+		if l.text != "" {
+			content = true
+		}
+		p.lines = append(p.lines, l)
+	}
+	return p, nil
+}
+
+// splitComment 分离 "#" 开头的注释, 引号中的 "#" 不是注释.
+func splitComment(s string) (text, comment string) {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" \t[{,:-", s[i-1]) != -1 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return strings.TrimRight(s[:i], " \t"), s[i:]
+		}
+	}
+	return strings.TrimRight(s, " \t"), ""
+}
+
+// skip 跳过空行和注释行, 返回注释.
+func (p *parser) skip() (comments []string) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		l := p.lines[p.pos]
+		if l.text != "" {
+			break
+		}
+		if l.comment != "" {
+			comments = append(comments, l.comment)
+		}
+	}
+	return
+}
+
+func (p *parser) peek() *line {
+	if p.pos < len(p.lines) {
+		return &p.lines[p.pos]
+	}
+	return nil
+}
+
+func isEntry(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// mappingKey 返回 mapping 中 key 与值的分隔位置, 不是 mapping 时返回 -1.
+func mappingKey(text string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case i == 0 && (c == '[' || c == '{'):
+			return -1
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+// parse 解析从当前行开始, 缩进不小于 indent 的节点.
+func (p *parser) parse(indent int) (*node, error) {
+	pos := p.pos
+	p.skip()
+	l := p.peek()
+	if l == nil || l.indent < indent {
+		p.pos = pos
+		return nil, nil
+	}
+
+	// 注释留给 mapping 和 sequence 的第一个元素
+	switch {
+	case isEntry(l.text):
+		p.pos = pos
+		return p.sequence(l.indent)
+	case mappingKey(l.text) != -1:
+		p.pos = pos
+		return p.mapping(l.indent)
+	}
+
+	n, err := p.inline(l.text, l.n)
+	if err != nil {
+		return nil, err
+	}
+	n.eol = l.comment
+	p.pos++
+	return n, nil
+}
+
+func (p *parser) mapping(indent int) (*node, error) {
+	m := &node{kind: mappingNode}
+	if l := p.peek(); l != nil {
+		m.line = l.n
+	}
+
+	for {
+		pos := p.pos
+		comments := p.skip()
+		l := p.peek()
+		if l == nil || l.indent < indent || l.indent == indent && isEntry(l.text) {
+			p.pos = pos
+			return m, nil
+		}
+		if l.indent > indent {
+			return nil, p.errorf(l.n, "bad indentation")
+		}
+
+		i := mappingKey(l.text)
+		if i == -1 {
+			return nil, p.errorf(l.n, "expected a mapping key")
+		}
+
+		key := strings.TrimSpace(l.text[:i])
+		if key != "" && (key[0] == '"' || key[0] == '\'') {
+			v, err := unquote(key)
+			if err != nil {
+				return nil, p.errorf(l.n, "%v", err)
+			}
+			key = v
+		} else if err := indicator(key); err != nil {
+			return nil, p.errorf(l.n, "%v", err)
+		}
+		for _, k := range m.keys {
+			if k == key {
+				return nil, p.errorf(l.n, "duplicate key %q", key)
+			}
+		}
+
+		child, err := p.value(l, strings.TrimSpace(l.text[i+1:]), indent)
+		if err != nil {
+			return nil, err
+		}
+		child.comments = comments
+		m.keys = append(m.keys, key)
+		m.children = append(m.children, child)
+	}
+}
+
+func (p *parser) sequence(indent int) (*node, error) {
+	s := &node{kind: sequenceNode}
+	if l := p.peek(); l != nil {
+		s.line = l.n
+	}
+
+	for {
+		pos := p.pos
+		comments := p.skip()
+		l := p.peek()
+		if l == nil || l.indent < indent || !isEntry(l.text) {
+			p.pos = pos
+			return s, nil
+		}
+		if l.indent > indent {
+			return nil, p.errorf(l.n, "bad indentation")
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+		var child *node
+		var err error
+		if rest != "" && (isEntry(rest) || mappingKey(rest) != -1) {
+			// "- key: value" 和 "- - item", 把当前行改写为更深的缩进后重新解析
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+			child, err = p.parse(l.indent)
+		} else {
+			child, err = p.value(l, rest, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		child.comments = append(comments, child.comments...)
+		s.children = append(s.children, child)
+	}
+}
+
+// value 解析当前行中 key 或 "-" 之后的值 s, 值为空时解析下属的节点.
+func (p *parser) value(l *line, s string, indent int) (*node, error) {
+	n, eol := l.n, l.comment
+	p.pos++
+
+	if s == "" {
+		child, err := p.parse(indent + 1)
+		if err == nil && child == nil {
+			// key 之后与 key 对齐的 "- item"
+			if next := p.peek(); next != nil && next.indent == indent && isEntry(next.text) {
+				child, err = p.sequence(indent)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			return nil, p.errorf(n, "null value is not supported")
+		}
+		if child.kind != scalarNode && eol != "" {
+			child.eol = eol
+		}
+		return child, nil
+	}
+
+	if s[0] == '|' || s[0] == '>' {
+		v, err := p.block(s, indent, n)
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: scalarNode, value: v, eol: eol, line: n}, nil
+	}
+
+	child, err := p.inline(s, n)
+	if err != nil {
+		return nil, err
+	}
+	child.eol = eol
+	return child, nil
+}
+
+// block 解析 "|" 和 ">" 开头的多行字符串, 支持 "-" 和 "+" 指示符.
+func (p *parser) block(header string, indent, n int) (string, error) {
+	folded := header[0] == '>'
+	chomp := strings.TrimSpace(header[1:])
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return "", p.errorf(n, "unsupported block scalar header %q", header)
+	}
+
+	var lines []string
+	width := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		l := p.lines[p.pos]
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if width == -1 {
+			width = l.indent
+		}
+		if l.indent < width {
+			return "", p.errorf(l.n, "bad indentation")
+		}
+		lines = append(lines, l.raw[width:])
+	}
+
+	// 尾部的空行交给 chomp 处理
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	trailing := len(lines) - end
+	lines = lines[:end]
+
+	var s string
+	if folded {
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "":
+				s += "\n"
+			case lines[i-1] == "":
+			default:
+				s += " "
+			}
+			s += line
+		}
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+
+	switch chomp {
+	case "-":
+	case "+":
+		s += "\n" + strings.Repeat("\n", trailing)
+	default:
+		if len(lines) != 0 {
+			s += "\n"
+		}
+	}
+	return s, nil
+}
+
+// inline 解析单行中的值, 包括 flow 形式的 [...] 和 {...}.
+func (p *parser) inline(s string, n int) (*node, error) {
+	f := &flow{s: s}
+	v, err := f.node()
+	if err == nil {
+		f.space()
+		if f.i != len(f.s) {
+			err = fmt.Errorf("unexpected %q", f.s[f.i:])
+		}
+	}
+	if err != nil {
+		return nil, p.errorf(n, "%v", err)
+	}
+	setLine(v, n)
+	return v, nil
+}
+
+func setLine(v *node, n int) {
+	v.line = n
+	for _, c := range v.children {
+		setLine(c, n)
+	}
+}
+
+type flow struct {
+	s string
+	i int
+}
+
+func (f *flow) space() {
+	for f.i < len(f.s) && f.s[f.i] == ' ' {
+		f.i++
+	}
+}
+
+func (f *flow) node() (*node, error) {
+	f.space()
+	if f.i == len(f.s) {
+		return nil, fmt.Errorf("null value is not supported")
+	}
+
+	switch f.s[f.i] {
+	case '[':
+		f.i++
+		s := &node{kind: sequenceNode}
+		for {
+			f.space()
+			if f.i == len(f.s) {
+				// 跨行的 flow 形式
+				return nil, fmt.Errorf("unclosed flow collection")
+			}
+			if f.s[f.i] == ']' {
+				f.i++
+				return s, nil
+			}
+			child, err := f.node()
+			if err != nil {
+				return nil, err
+			}
+			s.children = append(s.children, child)
+			if err = f.sep(']'); err != nil {
+				return nil, err
+			}
+		}
+
+	case '{':
+		f.i++
+		m := &node{kind: mappingNode}
+		for {
+			f.space()
+			if f.i == len(f.s) {
+				// 跨行的 flow 形式
+				return nil, fmt.Errorf("unclosed flow collection")
+			}
+			if f.s[f.i] == '}' {
+				f.i++
+				return m, nil
+			}
+			key, err := f.scalar(true)
+			if err != nil {
+				return nil, err
+			}
+			f.space()
+			if f.i == len(f.s) || f.s[f.i] != ':' {
+				return nil, fmt.Errorf("expected ':' in flow mapping")
+			}
+			f.i++
+			child, err := f.node()
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key.(string))
+			m.children = append(m.children, child)
+			if err = f.sep('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s, err := f.scalar(false)
+	if err != nil {
+		return nil, err
+	}
+	return &node{kind: scalarNode, value: s}, nil
+}
+
+// sep 处理 flow 中元素之后的 "," 或结束符.
+func (f *flow) sep(end byte) error {
+	f.space()
+	if f.i == len(f.s) {
+		return fmt.Errorf("unclosed flow collection")
+	}
+	switch f.s[f.i] {
+	case ',':
+		f.i++
+	case end:
+	default:
+		return fmt.Errorf("unexpected %q", f.s[f.i:])
+	}
+	return nil
+}
+
+/**
+scalar 读取 flow 中的标量, key 为 true 时返回 string, 否则返回推断类型后的值.
+*/
+func (f *flow) scalar(key bool) (interface{}, error) {
+	f.space()
+	start := f.i
+
+	if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+		q := f.s[f.i]
+		for f.i++; f.i < len(f.s); f.i++ {
+			if f.s[f.i] == '\\' && q == '"' {
+				f.i++
+				continue
+			}
+			if f.s[f.i] != q {
+				continue
+			}
+			// '' 是单引号的转义
+			if q == '\'' && f.i+1 < len(f.s) && f.s[f.i+1] == '\'' {
+				f.i++
+				continue
+			}
+			f.i++
+			return unquote(f.s[start:f.i])
+		}
+		return nil, fmt.Errorf("unclosed string %s", f.s[start:])
+	}
+
+	// 顶层的 plain scalar 可以含有 ",[]{}", 只在 flow 中作为分隔符
+	inFlow := start != 0 || key
+	for ; f.i < len(f.s); f.i++ {
+		c := f.s[f.i]
+		if inFlow && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		if c == ':' && (f.i+1 == len(f.s) || f.s[f.i+1] == ' ') && inFlow {
+			break
+		}
+	}
+
+	s := strings.TrimSpace(f.s[start:f.i])
+	if err := indicator(s); err != nil {
+		return nil, err
+	}
+	if key {
+		return s, nil
+	}
+	return resolve(s)
+}
+
+// indicator 检查 plain scalar 是否以不支持的指示符开头, 比如 anchor, alias 和 tag.
+func indicator(s string) error {
+	if s == "" {
+		return nil
+	}
+	switch s[0] {
+	case '&':
+		return fmt.Errorf("anchor %s is not supported", s)
+	case '*':
+		return fmt.Errorf("alias %s is not supported", s)
+	case '!':
+		return fmt.Errorf("tag %s is not supported", s)
+	case '?':
+		if len(s) == 1 || s[1] == ' ' {
+			return fmt.Errorf("complex key is not supported")
+		}
+	case '@', '`':
+		return fmt.Errorf("reserved indicator %q", s[0])
+	}
+	return nil
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '"' {
+		return strconv.Unquote(s)
+	}
+	if len(s) < 2 || s[len(s)-1] != '\'' {
+		return "", fmt.Errorf("unclosed string %s", s)
+	}
+	return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+}
+
+// resolve 按照 YAML 1.2 core schema 推断 plain scalar 的类型, TOML 不支持 null.
+func resolve(s string) (interface{}, error) {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil, fmt.Errorf("null value is not supported")
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1), nil
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1), nil
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN(), nil
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	if strings.HasPrefix(s, "0x") {
+		if i, err := strconv.ParseInt(s[2:], 16, 64); err == nil {
+			return i, nil
+		}
+	}
+	if strings.HasPrefix(s, "0o") {
+		if i, err := strconv.ParseInt(s[2:], 8, 64); err == nil {
+			return i, nil
+		}
+	}
+	if strings.Trim(s, "+-0123456789.eE") == "" {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return s, nil
+}
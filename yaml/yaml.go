@@ -0,0 +1,327 @@
+/**
+Package yaml 在 YAML 文档和 Toml 之间转换, 只依赖标准库.
+
+支持配置文件常用的 YAML 子集:
+	block mapping 和 block sequence, 缩进只能使用空格
+	单行的 flow 形式 [...] 和 {...}, 可以嵌套, 不能跨行
+	plain, 单引号和双引号字符串, 双引号字符串的转义同 strconv.Unquote
+	"|" 和 ">" 多行字符串, 可以使用 "-" 和 "+" 指示符, 不支持缩进指示符, 比如 "|2"
+	"#" 注释, 文档开头的 "---" 和 "%" 指令, 文档末尾的 "..."
+以下内容返回错误:
+	anchor, alias 和 tag, 比如 &a, *a, !!str
+	复杂 key, 也就是 "? " 开头的 key
+	多文档, 也就是内容之后的 "---", 或者 "..." 之后的内容
+	跨行的 flow 形式
+	null 值, 包括空值, TOML 没有 null
+
+对应关系:
+	mapping                       TableName
+	元素都是 mapping 的 sequence    ArrayOfTables
+	其他 sequence                  Array 或 typeArray, 必须满足 Value.Add 的要求
+	标量按 YAML 1.2 core schema 推断类型, 时间戳对应 Datetime
+注释绑定到随后的 key 或 sequence 元素, 行尾注释绑定到当前行的值, 文档末尾的注释绑定到 Toml.
+*/
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/achun/tom-toml"
+)
+
+// Parse 把 YAML 文档转换为 Toml, 文档的根必须是 mapping.
+func Parse(source []byte) (toml.Toml, error) {
+	p, err := newParser(source)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := p.parse(0)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := p.skip()
+	if l := p.peek(); l != nil {
+		return nil, p.errorf(l.n, "unexpected %q", l.text)
+	}
+
+	tm := toml.New()
+	if root == nil {
+		tm.SetComments(comments)
+		return tm, nil
+	}
+	if root.kind != mappingNode {
+		return nil, p.errorf(root.line, "document must be a mapping")
+	}
+
+	if err = build(tm, "", root); err != nil {
+		return nil, err
+	}
+	tm.SetComments(comments)
+	return tm, nil
+}
+
+// LoadFile 读取并转换 YAML 文件.
+func LoadFile(path string) (toml.Toml, error) {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(source)
+}
+
+func validKey(key string) bool {
+	return key != "" && strings.IndexAny(key, " \t\r\n=#.[]\"") == -1
+}
+
+func build(tm toml.Toml, prefix string, m *node) error {
+	for i, key := range m.keys {
+		c := m.children[i]
+		if !validKey(key) {
+			return fmt.Errorf("yaml: line %d: invalid key %q", c.line, key)
+		}
+		path := prefix + key
+
+		var it toml.Item
+		switch {
+		case c.kind == mappingNode:
+			it = toml.GenItem(toml.TableName)
+			tm[path] = it
+			if err := build(tm, path+".", c); err != nil {
+				return err
+			}
+
+		case isTables(c):
+			it = toml.GenItem(toml.ArrayOfTables)
+			for _, e := range c.children {
+				et := toml.New()
+				et.SetComments(e.comments)
+				et.SetComment(e.eol)
+				if err := build(et, "", e); err != nil {
+					return err
+				}
+				it.AddTable(et)
+			}
+			tm[path] = it
+			// 元素的注释已经保存在元素中
+			c.comments, c.eol = nil, ""
+
+		default:
+			v, err := value(c)
+			if err != nil {
+				return err
+			}
+			it = toml.Item{Value: v}
+			tm[path] = it
+		}
+
+		it.SetComments(c.comments)
+		it.SetComment(c.eol)
+	}
+	return nil
+}
+
+func isTables(n *node) bool {
+	if n.kind != sequenceNode || len(n.children) == 0 {
+		return false
+	}
+	for _, c := range n.children {
+		if c.kind != mappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+func value(n *node) (*toml.Value, error) {
+	x := n.value
+	if n.kind != scalarNode {
+		a := make([]interface{}, len(n.children))
+		for i, c := range n.children {
+			if c.kind == mappingNode {
+				return nil, fmt.Errorf("yaml: line %d: mapping in a mixed sequence is %v", c.line, toml.NotSupported)
+			}
+			v, err := value(c)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = v
+		}
+		x = a
+	}
+
+	v, err := toml.ValueOf(x)
+	if err != nil {
+		return nil, fmt.Errorf("yaml: line %d: %v", n.line, err)
+	}
+	return v, nil
+}
+
+/**
+Marshal 把 Toml 转换为 YAML 文档, 注释和元素的次序被保留.
+TableName 输出为 mapping, ArrayOfTables 输出为 mapping 的 sequence,
+数组输出为 flow 形式, 比如 [1, 2, 3].
+字符串在需要时输出为双引号形式, 以便 Parse 能够得到相同的类型.
+*/
+func Marshal(tm toml.Toml) ([]byte, error) {
+	var buf bytes.Buffer
+	writeMapping(&buf, tm, "", "")
+
+	id := tm.Id()
+	writeComments(&buf, id.Comments(), "")
+	return buf.Bytes(), nil
+}
+
+// children 返回 prefix 之下直接的子元素名称, 按首次出现的次序排列.
+func children(tm toml.Toml, prefix string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, key := range tm.Keys() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		name := key[len(prefix):]
+		if pos := strings.IndexByte(name, '.'); pos != -1 {
+			name = name[:pos]
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func writeComments(buf *bytes.Buffer, comments []string, indent string) {
+	for _, s := range comments {
+		buf.WriteString(indent + s + "\n")
+	}
+}
+
+func comment(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " " + s
+}
+
+func writeMapping(buf *bytes.Buffer, tm toml.Toml, prefix, indent string) {
+	for _, name := range children(tm, prefix) {
+		path := prefix + name
+		key := indent + scalar(name) + ":"
+		it, ok := tm[path]
+
+		if !ok || it.Kind() == toml.TableName {
+			eol := ""
+			if ok {
+				writeComments(buf, it.Comments(), indent)
+				eol = comment(it.Comment())
+			}
+			sub := path + "."
+			if len(children(tm, sub)) == 0 {
+				buf.WriteString(key + " {}" + eol + "\n")
+				continue
+			}
+			buf.WriteString(key + eol + "\n")
+			writeMapping(buf, tm, sub, indent+"  ")
+			continue
+		}
+
+		writeComments(buf, it.Comments(), indent)
+		if it.Kind() != toml.ArrayOfTables {
+			buf.WriteString(key + " " + flowString(it.Value) + comment(it.Comment()) + "\n")
+			continue
+		}
+
+		buf.WriteString(key + comment(it.Comment()) + "\n")
+		for _, et := range it.TomlArray() {
+			id := et.Id()
+			writeComments(buf, id.Comments(), indent+"  ")
+			if id.Comment() != "" {
+				writeComments(buf, []string{id.Comment()}, indent+"  ")
+			}
+
+			var eb bytes.Buffer
+			writeMapping(&eb, et, "", "")
+			lines := strings.Split(strings.TrimSuffix(eb.String(), "\n"), "\n")
+
+			// "- " 放在第一个不是注释的行
+			first := 0
+			for first < len(lines) && strings.HasPrefix(lines[first], "#") {
+				first++
+			}
+			for i, line := range lines {
+				switch {
+				case eb.Len() == 0:
+					line = indent + "  - {}"
+				case i == first:
+					line = indent + "  - " + line
+				default:
+					line = indent + "    " + line
+				}
+				buf.WriteString(line + "\n")
+			}
+		}
+	}
+}
+
+// flowString 以 flow 形式输出值.
+func flowString(v *toml.Value) string {
+	switch v.Kind() {
+	case toml.String:
+		return scalar(v.String())
+	case toml.Integer:
+		return strconv.FormatInt(v.Int(), 10)
+	case toml.Float:
+		f := v.Float()
+		switch {
+		case math.IsInf(f, 1):
+			return ".inf"
+		case math.IsInf(f, -1):
+			return "-.inf"
+		case math.IsNaN(f):
+			return ".nan"
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if strings.IndexAny(s, ".e") == -1 {
+			s += ".0"
+		}
+		return s
+	case toml.Boolean:
+		return strconv.FormatBool(v.Boolean())
+	case toml.Datetime:
+		return v.Datetime().Format(time.RFC3339Nano)
+	}
+
+	ss := make([]string, v.Len())
+	for i := range ss {
+		ss[i] = flowString(v.Index(i))
+	}
+	return "[" + strings.Join(ss, ", ") + "]"
+}
+
+// scalar 在 s 不能作为 plain scalar 时返回双引号形式.
+func scalar(s string) string {
+	if s == "" || strings.TrimSpace(s) != s ||
+		strings.IndexAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") != -1 ||
+		strings.IndexAny(s, ",[]{}\\\"\r\n\t") != -1 ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return strconv.Quote(s)
+	}
+	if x, err := resolve(s); err != nil || x != s {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if !strconv.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
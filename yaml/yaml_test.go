@@ -0,0 +1,188 @@
+package yaml
+
+import (
+	"github.com/achun/testing-want"
+	"github.com/achun/tom-toml"
+	"testing"
+	"time"
+)
+
+const source = `# This is a YAML document.
+---
+title: YAML Example
+
+owner: # owner information
+  name: Tom Preston-Werner
+  dob: 1979-05-27T07:32:00Z # First class dates? Why not?
+
+database:
+  server: "192.168.1.1"
+  ports: [8001, 8001, 8002]
+  enabled: true
+  ratio: 0.5
+  motd: |
+    Welcome
+    to the server
+  note: >-
+    folded
+    text
+
+servers:
+  # You can indent as you please.
+  alpha:
+    ip: 10.0.0.1
+    dc: 'eq''dc10'
+  beta: {ip: 10.0.0.2, dc: eqdc10}
+
+clients:
+  data:
+    - [gamma, delta]
+    - [1, 2]
+  hosts:
+  - alpha
+  - omega
+
+products:
+  # the first product
+  - name: Hammer # hammer time
+    sku: 738594937
+  - name: Nail
+    sku: 284758393
+    color: gray
+    tags: []
+
+# last comments
+`
+
+func TestParse(t *testing.T) {
+	wt := want.T(t)
+	tm, err := Parse([]byte(source))
+	wt.Nil(err)
+
+	wt.Equal(tm["title"].String(), "YAML Example")
+	wt.Equal(tm["title"].Comments(), []string{"# This is a YAML document."})
+	wt.Equal(tm["owner"].Kind(), toml.TableName)
+	wt.Equal(tm["owner"].Comment(), "# owner information")
+	wt.Equal(tm["owner.dob"].Datetime(), time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC))
+	wt.Equal(tm["owner.dob"].Comment(), "# First class dates? Why not?")
+
+	wt.Equal(tm["database.server"].String(), "192.168.1.1")
+	wt.Equal(tm["database.ports"].IntArray(), []int64{8001, 8001, 8002})
+	wt.True(tm["database.enabled"].Boolean())
+	wt.Equal(tm["database.ratio"].Float(), 0.5)
+	wt.Equal(tm["database.motd"].String(), "Welcome\nto the server\n")
+	wt.Equal(tm["database.note"].String(), "folded text")
+
+	wt.Equal(tm["servers.alpha"].Comments(), []string{"# You can indent as you please."})
+	wt.Equal(tm["servers.alpha.dc"].String(), "eq'dc10")
+	wt.Equal(tm["servers.beta.ip"].String(), "10.0.0.2")
+
+	wt.Equal(tm["clients.data"].Kind(), toml.Array)
+	wt.Equal(tm.Get("clients.data[0][1]").String(), "delta")
+	wt.Equal(tm["clients.hosts"].StringArray(), []string{"alpha", "omega"})
+
+	wt.Equal(tm["products"].Kind(), toml.ArrayOfTables)
+	wt.Equal(tm["products"].Len(), 2)
+	id := tm["products"].Table(0).Id()
+	wt.Equal(id.Comments(), []string{"# the first product"})
+	wt.Equal(tm.Get("products[0].name").Comment(), "# hammer time")
+	wt.Equal(tm.Get("products[1].color").String(), "gray")
+	wt.Equal(tm.Get("products[1].tags").Len(), 0)
+
+	id = tm.Id()
+	wt.Equal(id.Comments(), []string{"# last comments"})
+
+	ntm, err := toml.Parse([]byte(tm.String()))
+	wt.Nil(err, tm.String())
+	wt.Equal(ntm.Get("products[0].sku").Integer(), 738594937)
+
+	for _, s := range []string{
+		"a: 1\n b: 2",
+		"a: [1, 2",
+		"a:\n",
+		"a: ~",
+		"- a",
+		"a: 1\na: 2",
+		"a: [1, a]",
+		"a b: 1",
+		"a:\n  - 1\n  - b: 2",
+		"a: |2\n  x",
+		"a: 1\n\tb: 2",
+	} {
+		_, err = Parse([]byte(s))
+		wt.NotNil(err, s)
+	}
+}
+
+func TestParseSubset(t *testing.T) {
+	wt := want.T(t)
+
+	// 文档标记, 指令和 flow 形式的嵌套
+	tm, err := Parse([]byte("%YAML 1.2\n---\na: {b: [1, 2], c: {d: x}}\ns: |-\n  one\n\n  two\n...\n# end\n"))
+	wt.Nil(err)
+	wt.Equal(tm.Get("a.b").IntArray(), []int64{1, 2})
+	wt.Equal(tm.Get("a.c.d").String(), "x")
+	wt.Equal(tm.Get("s").String(), "one\n\ntwo")
+	id := tm.Id()
+	wt.Equal(id.Comments(), []string{"# end"})
+
+	for s, msg := range map[string]string{
+		"a: &x 1\nb: *x":       "yaml: line 1: anchor &x 1 is not supported",
+		"a: *x":                "yaml: line 1: alias *x is not supported",
+		"a: !!str 1":           "yaml: line 1: tag !!str 1 is not supported",
+		"a: [1, &x 2]":         "yaml: line 1: anchor &x 2 is not supported",
+		"&x a: 1":              "yaml: line 1: anchor &x a is not supported",
+		"? a\n: 1":             "yaml: line 1: complex key is not supported",
+		"a: 1\n---\nb: 2":      "yaml: line 2: multiple documents are not supported",
+		"a: 1\n...\n---\nb: 2": "yaml: line 3: multiple documents are not supported",
+		"a: 1\n...\nb: 2":      "yaml: line 3: multiple documents are not supported",
+		"--- a: 1":             "yaml: line 1: multiple documents are not supported",
+		"a: 1\n%YAML 1.2":      "yaml: line 2: unexpected directive",
+		"a: [1,\n  2]":         "yaml: line 1: unclosed flow collection",
+		"a: {b: 1,\n  c: 2}":   "yaml: line 1: unclosed flow collection",
+		"a: |+2\n  x":          "yaml: line 1: unsupported block scalar header \"|+2\"",
+		"a: >1\n  x":           "yaml: line 1: unsupported block scalar header \">1\"",
+		"a: `x`":               "yaml: line 1: reserved indicator '`'",
+	} {
+		_, err = Parse([]byte(s))
+		if wt.NotNil(err, s) {
+			wt.Equal(err.Error(), msg, s)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	wt := want.T(t)
+	tm, err := toml.LoadFile("../tests/example.toml")
+	wt.Nil(err)
+
+	b, err := Marshal(tm)
+	wt.Nil(err)
+
+	ntm, err := Parse(b)
+	wt.Nil(err, string(b))
+	wt.True(toml.Equal(tm, ntm, toml.IgnoreComments, toml.IgnoreOrder), string(b))
+	wt.Equal(ntm["owner"].Comment(), "# owner information")
+	wt.Equal(ntm["servers.alpha"].Comments(), tm["servers.alpha"].Comments())
+	wt.Equal(ntm["servers.beta.country"].Comment(), "# This should be parsed as UTF-8")
+
+	tm, err = Parse([]byte(source))
+	wt.Nil(err)
+	b, err = Marshal(tm)
+	wt.Nil(err)
+	ntm, err = Parse(b)
+	wt.Nil(err, string(b))
+	wt.True(toml.Equal(tm, ntm, toml.IgnoreOrder), string(b))
+
+	tm = toml.New()
+	wt.Nil(tm.Set("a", "true"))
+	wt.Nil(tm.Set("b", "1: x"))
+	wt.Nil(tm.Set("c", 2.0))
+	wt.Nil(tm.Set("d", []string{"x, y", "#z"}))
+	b, err = Marshal(tm)
+	wt.Nil(err)
+	wt.Equal(string(b), "a: \"true\"\nb: \"1: x\"\nc: 2.0\nd: [\"x, y\", \"#z\"]\n")
+	ntm, err = Parse(b)
+	wt.Nil(err)
+	wt.True(toml.Equal(tm, ntm, toml.IgnoreOrder))
+}